@@ -6,11 +6,14 @@ import (
 
 	"github.com/consensys/gnark"
 	"github.com/consensys/gnark-crypto/ecc"
+	tedwards "github.com/consensys/gnark-crypto/ecc/twistededwards"
 	"github.com/consensys/gnark/frontend"
 	"github.com/consensys/gnark/std/algebra/sw_bls12377"
 	"github.com/consensys/gnark/std/algebra/sw_bls24315"
+	"github.com/consensys/gnark/std/algebra/twistededwards"
 	"github.com/consensys/gnark/std/hash/mimc"
 	"github.com/consensys/gnark/std/math/bits"
+	"github.com/consensys/gnark/std/signature/eddsa"
 )
 
 var (
@@ -79,6 +82,32 @@ func initSnippets() {
 		_ = mimc.Sum()
 	})
 
+	registerSnippet("twistededwards/ScalarMul", func(api frontend.API, newVariable func() frontend.Variable) {
+		curve, _ := twistededwards.NewEdCurve(api, tedwards.BN254)
+		_ = curve.ScalarMul(twistededwards.Point{X: newVariable(), Y: newVariable()}, newVariable())
+	}, ecc.BN254)
+
+	registerSnippet("twistededwards/ScalarMulBase", func(api frontend.API, newVariable func() frontend.Variable) {
+		curve, _ := twistededwards.NewEdCurve(api, tedwards.BN254)
+		_ = twistededwards.ScalarMulBase(curve, newVariable())
+	}, ecc.BN254)
+
+	registerSnippet("signature/eddsa", func(api frontend.API, newVariable func() frontend.Variable) {
+		curve, _ := twistededwards.NewEdCurve(api, tedwards.BN254)
+		h, _ := mimc.NewMiMC(api)
+		pubKey := eddsa.PublicKey{A: twistededwards.Point{X: newVariable(), Y: newVariable()}}
+		sig := eddsa.Signature{R: twistededwards.Point{X: newVariable(), Y: newVariable()}, S: newVariable()}
+		_ = eddsa.Verify(curve, sig, newVariable(), pubKey, &h)
+	}, ecc.BN254)
+
+	registerSnippet("signature/eddsa/NonMalleable", func(api frontend.API, newVariable func() frontend.Variable) {
+		curve, _ := twistededwards.NewEdCurve(api, tedwards.BN254)
+		h, _ := mimc.NewMiMC(api)
+		pubKey := eddsa.PublicKey{A: twistededwards.Point{X: newVariable(), Y: newVariable()}}
+		sig := eddsa.Signature{R: twistededwards.Point{X: newVariable(), Y: newVariable()}, S: newVariable()}
+		_ = eddsa.Verify(curve, sig, newVariable(), pubKey, &h, eddsa.WithNonMalleable())
+	}, ecc.BN254)
+
 	registerSnippet("pairing_bls12377", func(api frontend.API, newVariable func() frontend.Variable) {
 
 		var dummyG1 sw_bls12377.G1Affine
@@ -0,0 +1,83 @@
+/*
+Copyright © 2020 ConsenSys
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package stdtest provides helpers shared by the tests of the std gadgets.
+package stdtest
+
+import (
+	"github.com/consensys/gnark-crypto/ecc/bn254/fr"
+	edbn254 "github.com/consensys/gnark-crypto/ecc/bn254/twistededwards"
+	"github.com/consensys/gnark/frontend"
+)
+
+// Circuits returns the n circuits newCircuit(0), ..., newCircuit(n-1).
+//
+// test.Assert caches the compiled circuits by curve, backend, type and address, so circuits
+// of the same type that differ by their unexported configuration (curve, hash function,
+// options...) must each live at their own address for the whole test. Building them once
+// with Circuits, and not as temporaries, ensures it.
+func Circuits(n int, newCircuit func(i int) frontend.Circuit) []frontend.Circuit {
+	circuits := make([]frontend.Circuit, n)
+	for i := range circuits {
+		circuits[i] = newCircuit(i)
+	}
+	return circuits
+}
+
+// SmallOrderPointsBN254 returns the 8 points of small order of the twisted edwards curve of
+// BN254, that is the points T with [8]T = (0, 1). points[i] = [i]points[1], points[1] being
+// of order 8; in particular points[0] is the identity and points[4] = (0, -1).
+func SmallOrderPointsBN254() []edbn254.PointAffine {
+	curve := edbn254.GetEdwardsCurve()
+
+	// find a point of order 8: [order]P for a point P, until [4][order]P != (0, 1)
+	var T8 edbn254.PointAffine
+	for i := uint64(2); ; i++ {
+		var y, y2, num, den, x fr.Element
+		y.SetUint64(i)
+		// x² = (1 - y²) / (a - d*y²)
+		y2.Square(&y)
+		num.SetOne().Sub(&num, &y2)
+		den.Mul(&curve.D, &y2).Sub(&curve.A, &den)
+		num.Div(&num, &den)
+		if x.Sqrt(&num) == nil {
+			continue
+		}
+		P := edbn254.PointAffine{X: x, Y: y}
+		T8.ScalarMul(&P, &curve.Order)
+		var T2 edbn254.PointAffine
+		T2.Double(&T8).Double(&T2)
+		if !T2.X.IsZero() || !T2.Y.IsOne() {
+			break
+		}
+	}
+
+	points := make([]edbn254.PointAffine, 8)
+	points[0].Y.SetOne()
+	for i := 1; i < len(points); i++ {
+		points[i].Add(&points[i-1], &T8)
+	}
+	return points
+}
+
+// AddOrder2BN254 returns A + (0, -1), (0, -1) being the point of order 2. If A is in the
+// prime order subgroup, the result is on the curve but not in the subgroup.
+func AddOrder2BN254(A edbn254.PointAffine) edbn254.PointAffine {
+	var T, res edbn254.PointAffine
+	T.Y.SetOne().Neg(&T.Y)
+	res.Add(&A, &T)
+	return res
+}
@@ -0,0 +1,56 @@
+/*
+Copyright © 2020 ConsenSys
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package stdtest
+
+import (
+	"testing"
+
+	edbn254 "github.com/consensys/gnark-crypto/ecc/bn254/twistededwards"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSmallOrderPointsBN254(t *testing.T) {
+	assert := require.New(t)
+
+	points := SmallOrderPointsBN254()
+	assert.Len(points, 8)
+
+	var identity, order2 edbn254.PointAffine
+	identity.Y.SetOne()
+	order2.Y.SetOne().Neg(&order2.Y)
+	assert.True(points[0].Equal(&identity))
+	assert.True(points[4].Equal(&order2))
+
+	// points[1] is of order 8
+	for i := range points {
+		assert.True(points[i].IsOnCurve())
+		var next edbn254.PointAffine
+		next.Add(&points[i], &points[1])
+		assert.True(next.Equal(&points[(i+1)%8]))
+		if i > 0 {
+			assert.False(points[i].Equal(&identity))
+		}
+	}
+
+	// A + (0, -1) is another point of the curve, and (A + (0, -1)) + (0, -1) = A
+	A := points[1]
+	AT := AddOrder2BN254(A)
+	assert.True(AT.IsOnCurve())
+	assert.False(AT.Equal(&A))
+	back := AddOrder2BN254(AT)
+	assert.True(back.Equal(&A))
+}
@@ -1,6 +1,8 @@
 package twistededwards
 
 import (
+	"math/big"
+
 	"github.com/consensys/gnark-crypto/ecc/twistededwards"
 	"github.com/consensys/gnark/frontend"
 )
@@ -61,3 +63,30 @@ func (c *curve) DoubleBaseScalarMul(p1, p2 Point, s1, s2 frontend.Variable) Poin
 	p.doubleBaseScalarMul(c.api, &p1, &p2, s1, s2, c.params)
 	return p
 }
+
+// The functions below only rely on the methods of Curve, so that they apply to any
+// implementation of the interface.
+
+// Select returns p1 if b is 1 and p0 if b is 0, for instance to order the two children of a
+// node from a path bit. Note that the points are in the reverse order of api.Select.
+func Select(curve Curve, b frontend.Variable, p0, p1 Point) Point {
+	api := curve.API()
+	return Point{
+		X: api.Select(b, p1.X, p0.X),
+		Y: api.Select(b, p1.Y, p0.Y),
+	}
+}
+
+// AssertScalarInRange asserts that scalar is in [0, order), order being the order of the
+// prime subgroup, so that it is the canonical representative of its class.
+func AssertScalarInRange(curve Curve, scalar frontend.Variable) {
+	curve.API().AssertIsLessOrEqual(scalar, new(big.Int).Sub(curve.Params().Order, big.NewInt(1)))
+}
+
+// ScalarMulBase computes [scalar]Base, Base being the base point of curve.Params().
+// The multiples of the base point are precomputed, which makes it cheaper than ScalarMul.
+func ScalarMulBase(curve Curve, scalar frontend.Variable) Point {
+	var p Point
+	p.scalarMulBase(curve.API(), scalar, curve.Params())
+	return p
+}
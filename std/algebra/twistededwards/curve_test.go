@@ -18,9 +18,12 @@ package twistededwards
 
 import (
 	"crypto/rand"
+	"encoding/json"
+	"errors"
 	"math/big"
 	"testing"
 
+	"github.com/consensys/gnark-crypto/ecc"
 	tbls12377 "github.com/consensys/gnark-crypto/ecc/bls12-377/twistededwards"
 	tbls12381_bandersnatch "github.com/consensys/gnark-crypto/ecc/bls12-381/bandersnatch"
 	tbls12381 "github.com/consensys/gnark-crypto/ecc/bls12-381/twistededwards"
@@ -31,6 +34,8 @@ import (
 	"github.com/consensys/gnark-crypto/ecc/twistededwards"
 
 	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/frontend/cs/r1cs"
+	"github.com/consensys/gnark/internal/stdtest"
 	"github.com/consensys/gnark/test"
 )
 
@@ -83,6 +88,43 @@ func TestIsOnCurve(t *testing.T) {
 
 }
 
+func TestCurveParamsJSON(t *testing.T) {
+	assert := test.NewAssert(t)
+
+	expected := `{"a":"21888242871839275222246405745257275088548364400416034343698204186575808495616",` +
+		`"d":"12181644023421730124874158521699555681764249180949974110617291017600649128846",` +
+		`"cofactor":"8",` +
+		`"order":"2736030358979909402780800718157159386076813972158567259200215660948447373041",` +
+		`"base":["9671717474070082183213120605117400219616337014328744928644933853176787189663",` +
+		`"16950150798460657717958625567821834550301663161624707787222815936182638968203"]}`
+
+	data, err := CurveParamsJSON(twistededwards.BN254)
+	assert.NoError(err)
+	assert.Equal(expected, string(data))
+
+	for _, curve := range curves {
+		params, err := GetCurveParams(curve)
+		assert.NoError(err)
+		data, err := CurveParamsJSON(curve)
+		assert.NoError(err)
+
+		var decoded struct {
+			A, D, Cofactor, Order string
+			Base                  [2]string
+		}
+		assert.NoError(json.Unmarshal(data, &decoded))
+		assert.Equal(params.A.String(), decoded.A)
+		assert.Equal(params.D.String(), decoded.D)
+		assert.Equal(params.Cofactor.String(), decoded.Cofactor)
+		assert.Equal(params.Order.String(), decoded.Order)
+		assert.Equal(params.Base[0].String(), decoded.Base[0])
+		assert.Equal(params.Base[1].String(), decoded.Base[1])
+	}
+
+	_, err = CurveParamsJSON(twistededwards.ID(255))
+	assert.Error(err)
+}
+
 type addCircuit struct {
 	curveID               twistededwards.ID
 	P1, P2                Point
@@ -131,6 +173,17 @@ func (circuit *addCircuit) Define(api frontend.API) error {
 		api.AssertIsEqual(res.Y, circuit.NegResult.Y)
 	}
 
+	{
+		// P + (-P) is the identity (0, 1)
+		res := curve.Add(circuit.P1, curve.Neg(circuit.P1))
+		api.AssertIsEqual(res.X, 0)
+		api.AssertIsEqual(res.Y, 1)
+
+		res = curve.Add(circuit.fixedPoint, curve.Neg(circuit.fixedPoint))
+		api.AssertIsEqual(res.X, 0)
+		api.AssertIsEqual(res.Y, 1)
+	}
+
 	{
 		// scalar mul
 		res := curve.ScalarMul(circuit.P2, circuit.S2)
@@ -145,6 +198,15 @@ func (circuit *addCircuit) Define(api frontend.API) error {
 		api.AssertIsEqual(res.Y, circuit.ScalarMulResult.Y)
 	}
 
+	{
+		// scalar mul base
+		base := Point{X: curve.Params().Base[0], Y: curve.Params().Base[1]}
+		res := ScalarMulBase(curve, circuit.S1)
+		expected := curve.ScalarMul(base, circuit.S1)
+		api.AssertIsEqual(res.X, expected.X)
+		api.AssertIsEqual(res.Y, expected.Y)
+	}
+
 	{
 		// double scalar mul
 		res := curve.DoubleBaseScalarMul(circuit.P1, circuit.P2, circuit.S1, circuit.S2)
@@ -188,6 +250,293 @@ func TestCurve(t *testing.T) {
 	}
 }
 
+type selectCircuit struct {
+	curveID  twistededwards.ID
+	B        frontend.Variable
+	P0, P1   Point
+	Expected Point
+}
+
+func (circuit *selectCircuit) Define(api frontend.API) error {
+	curve, err := NewEdCurve(api, circuit.curveID)
+	if err != nil {
+		return err
+	}
+	res := Select(curve, circuit.B, circuit.P0, circuit.P1)
+	api.AssertIsEqual(res.X, circuit.Expected.X)
+	api.AssertIsEqual(res.Y, circuit.Expected.Y)
+	return nil
+}
+
+func TestSelect(t *testing.T) {
+	assert := test.NewAssert(t)
+
+	circuits := stdtest.Circuits(len(curves), func(i int) frontend.Circuit {
+		return &selectCircuit{curveID: curves[i]}
+	})
+	for i, curve := range curves {
+		snarkCurve, err := GetSnarkCurve(curve)
+		assert.NoError(err)
+
+		params, err := GetCurveParams(curve)
+		assert.NoError(err)
+
+		p0 := nativeScalarMulBase(params, curve, params.randomScalar())
+		p1 := nativeScalarMulBase(params, curve, params.randomScalar())
+
+		assert.SolvingSucceeded(circuits[i], &selectCircuit{B: 1, P0: p0, P1: p1, Expected: p1}, test.WithCurves(snarkCurve))
+		assert.SolvingSucceeded(circuits[i], &selectCircuit{B: 0, P0: p0, P1: p1, Expected: p0}, test.WithCurves(snarkCurve))
+		assert.SolvingFailed(circuits[i], &selectCircuit{B: 1, P0: p0, P1: p1, Expected: p0}, test.WithCurves(snarkCurve))
+		assert.SolvingFailed(circuits[i], &selectCircuit{B: 0, P0: p0, P1: p1, Expected: p1}, test.WithCurves(snarkCurve))
+
+		// b must be boolean
+		assert.SolvingFailed(circuits[i], &selectCircuit{B: 2, P0: p0, P1: p1, Expected: p1}, test.WithCurves(snarkCurve))
+	}
+}
+
+type scalarInRangeCircuit struct {
+	curveID twistededwards.ID
+	S       frontend.Variable
+}
+
+func (circuit *scalarInRangeCircuit) Define(api frontend.API) error {
+	curve, err := NewEdCurve(api, circuit.curveID)
+	if err != nil {
+		return err
+	}
+	AssertScalarInRange(curve, circuit.S)
+	return nil
+}
+
+func TestAssertScalarInRange(t *testing.T) {
+	assert := test.NewAssert(t)
+
+	circuits := stdtest.Circuits(len(curves), func(i int) frontend.Circuit {
+		return &scalarInRangeCircuit{curveID: curves[i]}
+	})
+	for i, curve := range curves {
+		snarkCurve, err := GetSnarkCurve(curve)
+		assert.NoError(err)
+
+		params, err := GetCurveParams(curve)
+		assert.NoError(err)
+
+		modulus := snarkCurve.Info().Fr.Modulus()
+		order := params.Order
+
+		for _, s := range []*big.Int{big.NewInt(0), big.NewInt(1), new(big.Int).Sub(order, big.NewInt(1)), params.randomScalar()} {
+			assert.SolvingSucceeded(circuits[i], &scalarInRangeCircuit{S: s}, test.WithCurves(snarkCurve))
+		}
+
+		// S + order is the same scalar, but is rejected
+		for _, s := range []*big.Int{order, new(big.Int).Add(order, big.NewInt(1)), new(big.Int).Sub(modulus, big.NewInt(1))} {
+			assert.SolvingFailed(circuits[i], &scalarInRangeCircuit{S: s}, test.WithCurves(snarkCurve))
+		}
+	}
+}
+
+type scalarMulBaseCircuit struct {
+	curveID twistededwards.ID
+	S       frontend.Variable
+	R       Point
+}
+
+func (circuit *scalarMulBaseCircuit) Define(api frontend.API) error {
+	curve, err := NewEdCurve(api, circuit.curveID)
+	if err != nil {
+		return err
+	}
+
+	res := ScalarMulBase(curve, circuit.S)
+	api.AssertIsEqual(res.X, circuit.R.X)
+	api.AssertIsEqual(res.Y, circuit.R.Y)
+
+	return nil
+}
+
+// TestScalarMulBaseMatchesNative checks the in-circuit fixed-base scalar multiplication
+// against gnark-crypto on edge-case and random scalars.
+func TestScalarMulBaseMatchesNative(t *testing.T) {
+	assert := test.NewAssert(t)
+
+	const nbRandomScalars = 8
+
+	circuits := stdtest.Circuits(len(curves), func(i int) frontend.Circuit {
+		return &scalarMulBaseCircuit{curveID: curves[i]}
+	})
+	for i, curve := range curves {
+		snarkCurve, err := GetSnarkCurve(curve)
+		assert.NoError(err)
+
+		params, err := GetCurveParams(curve)
+		assert.NoError(err)
+
+		scalars := []*big.Int{
+			big.NewInt(0),
+			big.NewInt(1),
+			new(big.Int).Sub(params.Order, big.NewInt(1)),
+		}
+		for j := 0; j < nbRandomScalars; j++ {
+			scalars = append(scalars, params.randomScalar())
+		}
+
+		for _, s := range scalars {
+			var witness scalarMulBaseCircuit
+			witness.S = s
+			witness.R = nativeScalarMulBase(params, curve, s)
+			assert.SolvingSucceeded(circuits[i], &witness, test.WithCurves(snarkCurve))
+		}
+
+		// a wrong result must be rejected
+		var witness scalarMulBaseCircuit
+		witness.S = scalars[len(scalars)-1]
+		witness.R = nativeScalarMulBase(params, curve, scalars[len(scalars)-2])
+		assert.SolvingFailed(circuits[i], &witness, test.WithCurves(snarkCurve))
+	}
+}
+
+type toyCurveCircuit struct {
+	params *CurveParams
+	S      frontend.Variable
+	R      Point
+}
+
+func (circuit *toyCurveCircuit) Define(api frontend.API) error {
+	curve, err := NewEdCurveWithParams(api, circuit.params)
+	if err != nil {
+		return err
+	}
+
+	AssertScalarInRange(curve, circuit.S)
+	res := ScalarMulBase(curve, circuit.S)
+	api.AssertIsEqual(res.X, circuit.R.X)
+	api.AssertIsEqual(res.Y, circuit.R.Y)
+	base := Point{X: curve.Params().Base[0], Y: curve.Params().Base[1]}
+	res = curve.ScalarMul(base, circuit.S)
+	api.AssertIsEqual(res.X, circuit.R.X)
+	api.AssertIsEqual(res.Y, circuit.R.Y)
+
+	return nil
+}
+
+type paramsCopyCircuit struct {
+	params *CurveParams
+	S      frontend.Variable
+}
+
+func (circuit *paramsCopyCircuit) Define(api frontend.API) error {
+	curve, err := NewEdCurveWithParams(api, circuit.params)
+	if err != nil {
+		return err
+	}
+	order := new(big.Int).Set(circuit.params.Order)
+
+	// mutating the parameters afterwards doesn't change the curve
+	circuit.params.Order.SetUint64(1)
+	circuit.params.Base[0].SetUint64(0)
+	if curve.Params().Order.Cmp(order) != 0 || curve.Params().Base[0].Sign() == 0 {
+		return errors.New("curve parameters alias the caller's")
+	}
+	AssertScalarInRange(curve, circuit.S)
+	return nil
+}
+
+// TestNewEdCurveWithParams uses the subgroup of order 8 of the BN254 twisted edwards curve
+// as a toy curve.
+func TestNewEdCurveWithParams(t *testing.T) {
+	assert := test.NewAssert(t)
+
+	// [s]Base for s in [0, 8)
+	multiples := stdtest.SmallOrderPointsBN254()
+	T8 := multiples[1]
+
+	params, err := GetCurveParams(twistededwards.BN254)
+	assert.NoError(err)
+	toy := &CurveParams{
+		A:        params.A,
+		D:        params.D,
+		Cofactor: big.NewInt(1),
+		Order:    big.NewInt(8),
+	}
+	toy.Base[0] = new(big.Int)
+	toy.Base[1] = new(big.Int)
+	T8.X.ToBigIntRegular(toy.Base[0])
+	T8.Y.ToBigIntRegular(toy.Base[1])
+
+	circuit := toyCurveCircuit{params: toy}
+	for s := range multiples {
+		assert.SolvingSucceeded(&circuit, &toyCurveCircuit{S: s, R: Point{multiples[s].X, multiples[s].Y}}, test.WithCurves(ecc.BN254))
+	}
+	assert.SolvingFailed(&circuit, &toyCurveCircuit{S: 3, R: Point{multiples[5].X, multiples[5].Y}}, test.WithCurves(ecc.BN254))
+
+	// [8]Base = (0, 1) but 8 is out of the range of the toy curve
+	assert.SolvingFailed(&circuit, &toyCurveCircuit{S: 8, R: Point{0, 1}}, test.WithCurves(ecc.BN254))
+
+	// the parameters are copied
+	params, err = GetCurveParams(twistededwards.BN254)
+	assert.NoError(err)
+	_, err = frontend.Compile(ecc.BN254, r1cs.NewBuilder, &paramsCopyCircuit{params: params})
+	assert.NoError(err)
+
+	// the base point must be on the curve
+	offCurve := *toy
+	offCurve.Base[1] = big.NewInt(2)
+	_, err = frontend.Compile(ecc.BN254, r1cs.NewBuilder, &toyCurveCircuit{params: &offCurve})
+	assert.Error(err)
+	_, err = frontend.Compile(ecc.BN254, r1cs.NewBuilder, &toyCurveCircuit{params: &CurveParams{}})
+	assert.Error(err)
+}
+
+// nativeScalarMulBase returns [s]Base computed with gnark-crypto
+func nativeScalarMulBase(params *CurveParams, curveID twistededwards.ID, s *big.Int) Point {
+	switch curveID {
+	case twistededwards.BN254:
+		var p tbn254.PointAffine
+		p.X.SetBigInt(params.Base[0])
+		p.Y.SetBigInt(params.Base[1])
+		p.ScalarMul(&p, s)
+		return Point{p.X, p.Y}
+	case twistededwards.BLS12_381:
+		var p tbls12381.PointAffine
+		p.X.SetBigInt(params.Base[0])
+		p.Y.SetBigInt(params.Base[1])
+		p.ScalarMul(&p, s)
+		return Point{p.X, p.Y}
+	case twistededwards.BLS12_381_BANDERSNATCH:
+		var p tbls12381_bandersnatch.PointAffine
+		p.X.SetBigInt(params.Base[0])
+		p.Y.SetBigInt(params.Base[1])
+		p.ScalarMul(&p, s)
+		return Point{p.X, p.Y}
+	case twistededwards.BLS12_377:
+		var p tbls12377.PointAffine
+		p.X.SetBigInt(params.Base[0])
+		p.Y.SetBigInt(params.Base[1])
+		p.ScalarMul(&p, s)
+		return Point{p.X, p.Y}
+	case twistededwards.BLS24_315:
+		var p tbls24315.PointAffine
+		p.X.SetBigInt(params.Base[0])
+		p.Y.SetBigInt(params.Base[1])
+		p.ScalarMul(&p, s)
+		return Point{p.X, p.Y}
+	case twistededwards.BW6_633:
+		var p tbw6633.PointAffine
+		p.X.SetBigInt(params.Base[0])
+		p.Y.SetBigInt(params.Base[1])
+		p.ScalarMul(&p, s)
+		return Point{p.X, p.Y}
+	case twistededwards.BW6_761:
+		var p tbw6761.PointAffine
+		p.X.SetBigInt(params.Base[0])
+		p.Y.SetBigInt(params.Base[1])
+		p.ScalarMul(&p, s)
+		return Point{p.X, p.Y}
+	default:
+		panic("not implemented")
+	}
+}
+
 // testData generates random test data for given curve
 // returns p1, p2 and r, d such that p1 + p2 == r and p1 + p1 == d
 // returns rs1, rs12, s1, s2 such that rs1 = p2 * s2 and rs12 = p1*s1 + p2 * s2
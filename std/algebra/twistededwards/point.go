@@ -17,6 +17,8 @@ limitations under the License.
 package twistededwards
 
 import (
+	"math/big"
+
 	"github.com/consensys/gnark/frontend"
 )
 
@@ -142,6 +144,74 @@ func (p *Point) scalarMul(api frontend.API, p1 *Point, scalar frontend.Variable,
 	return p
 }
 
+// scalarMulBase computes the scalar multiplication of the base point of a twisted Edwards curve
+// curve: parameters of the Edwards curve
+// scal: scalar as a SNARK constraint
+// Since the base point is fixed, the multiples [k*4^i]Base (k=1,2,3) are precomputed
+// and each 2-bits window of the scalar costs a lookup in constants and an addition
+// (no doubling).
+func (p *Point) scalarMulBase(api frontend.API, scalar frontend.Variable, curve *CurveParams) *Point {
+
+	// first unpack the scalar
+	b := api.ToBinary(scalar)
+
+	modulus := api.Curve().Info().Fr.Modulus()
+
+	// g = [4^i]Base
+	g := [2]*big.Int{new(big.Int).Set(curve.Base[0]), new(big.Int).Set(curve.Base[1])}
+
+	res := Point{}
+	tmp := Point{}
+
+	for i := 0; i < len(b); i += 2 {
+		g2 := nativeAdd(g, g, curve, modulus)
+		if i+1 < len(b) {
+			g3 := nativeAdd(g2, g, curve, modulus)
+			tmp.X = api.Lookup2(b[i], b[i+1], 0, g[0], g2[0], g3[0])
+			tmp.Y = api.Lookup2(b[i], b[i+1], 1, g[1], g2[1], g3[1])
+		} else {
+			tmp.X = api.Select(b[i], g[0], 0)
+			tmp.Y = api.Select(b[i], g[1], 1)
+		}
+		if i == 0 {
+			res = tmp
+		} else {
+			res.add(api, &res, &tmp, curve)
+		}
+		g = nativeAdd(g2, g2, curve, modulus)
+	}
+
+	p.X = res.X
+	p.Y = res.Y
+
+	return p
+}
+
+// nativeAdd adds two constant points on a twisted Edwards curve defined over Z/modulusZ
+// it is used to precompute tables of constant points
+func nativeAdd(p1, p2 [2]*big.Int, curve *CurveParams, modulus *big.Int) [2]*big.Int {
+	var x1x2, y1y2, dxy, num, den big.Int
+
+	x1x2.Mul(p1[0], p2[0]).Mod(&x1x2, modulus)
+	y1y2.Mul(p1[1], p2[1]).Mod(&y1y2, modulus)
+	dxy.Mul(&x1x2, &y1y2).Mul(&dxy, curve.D).Mod(&dxy, modulus)
+
+	// x = (x1*y2 + y1*x2) / (1 + d*x1*x2*y1*y2)
+	x := new(big.Int).Mul(p1[0], p2[1])
+	num.Mul(p1[1], p2[0])
+	x.Add(x, &num)
+	den.Add(&dxy, big.NewInt(1)).ModInverse(&den, modulus)
+	x.Mul(x, &den).Mod(x, modulus)
+
+	// y = (y1*y2 - a*x1*x2) / (1 - d*x1*x2*y1*y2)
+	y := new(big.Int).Mul(curve.A, &x1x2)
+	y.Sub(&y1y2, y)
+	den.Sub(big.NewInt(1), &dxy).Mod(&den, modulus).ModInverse(&den, modulus)
+	y.Mul(y, &den).Mod(y, modulus)
+
+	return [2]*big.Int{x, y}
+}
+
 // doubleBaseScalarMul computes s1*P1+s2*P2
 // where P1 and P2 are points on a twisted Edwards curve
 // and s1, s2 scalars.
@@ -17,6 +17,7 @@ limitations under the License.
 package twistededwards
 
 import (
+	"encoding/json"
 	"errors"
 	"math/big"
 
@@ -93,6 +94,39 @@ func NewEdCurve(api frontend.API, id twistededwards.ID) (Curve, error) {
 	return &curve{api: api, params: params, endo: endo, id: id}, nil
 }
 
+// NewEdCurveWithParams returns a new Edwards curve defined by explicit parameters instead of
+// the standard ones of a twisted edwards ID, for instance to test against a toy curve.
+//
+// The curve must be defined over the native field of api and its base point must be on the curve.
+// params is copied, and no endomorphism is used.
+func NewEdCurveWithParams(api frontend.API, params *CurveParams) (Curve, error) {
+	if params == nil || params.A == nil || params.D == nil || params.Cofactor == nil || params.Order == nil ||
+		params.Base[0] == nil || params.Base[1] == nil {
+		return nil, errors.New("incomplete curve parameters")
+	}
+
+	// a*x² + y² = 1 + d*x²*y² for the base point
+	modulus := api.Curve().Info().Fr.Modulus()
+	var xx, yy, lhs, rhs big.Int
+	xx.Mul(params.Base[0], params.Base[0]).Mod(&xx, modulus)
+	yy.Mul(params.Base[1], params.Base[1]).Mod(&yy, modulus)
+	lhs.Mul(params.A, &xx).Add(&lhs, &yy).Mod(&lhs, modulus)
+	rhs.Mul(params.D, &xx).Mul(&rhs, &yy).Add(&rhs, big.NewInt(1)).Mod(&rhs, modulus)
+	if lhs.Cmp(&rhs) != 0 {
+		return nil, errors.New("base point is not on the curve")
+	}
+
+	p := &CurveParams{
+		A:        new(big.Int).Set(params.A),
+		D:        new(big.Int).Set(params.D),
+		Cofactor: new(big.Int).Set(params.Cofactor),
+		Order:    new(big.Int).Set(params.Order),
+		Base:     [2]*big.Int{new(big.Int).Set(params.Base[0]), new(big.Int).Set(params.Base[1])},
+	}
+
+	return &curve{api: api, params: p}, nil
+}
+
 func GetCurveParams(id twistededwards.ID) (*CurveParams, error) {
 	var params *CurveParams
 	switch id {
@@ -116,6 +150,36 @@ func GetCurveParams(id twistededwards.ID) (*CurveParams, error) {
 	return params, nil
 }
 
+// CurveParamsJSON returns the parameters of the twisted edwards curve, as returned by GetCurveParams,
+// encoded in JSON. All values are base 10 strings:
+//
+//	{
+//		"a": "...",
+//		"d": "...",
+//		"cofactor": "...",
+//		"order": "...",
+//		"base": ["<x>", "<y>"]
+//	}
+func CurveParamsJSON(id twistededwards.ID) ([]byte, error) {
+	params, err := GetCurveParams(id)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(struct {
+		A        string    `json:"a"`
+		D        string    `json:"d"`
+		Cofactor string    `json:"cofactor"`
+		Order    string    `json:"order"`
+		Base     [2]string `json:"base"`
+	}{
+		A:        params.A.String(),
+		D:        params.D.String(),
+		Cofactor: params.Cofactor.String(),
+		Order:    params.Order.String(),
+		Base:     [2]string{params.Base[0].String(), params.Base[1].String()},
+	})
+}
+
 // GetSnarkCurve returns the matching snark curve for a twisted edwards curve
 func GetSnarkCurve(id twistededwards.ID) (ecc.ID, error) {
 	switch id {
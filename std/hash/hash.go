@@ -17,7 +17,14 @@ limitations under the License.
 // Package hash provides an interface that hash functions (as gadget) should implement.
 package hash
 
-import "github.com/consensys/gnark/frontend"
+import (
+	"fmt"
+	"sync"
+
+	cryptohash "github.com/consensys/gnark-crypto/hash"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/logger"
+)
 
 type Hash interface {
 
@@ -30,3 +37,36 @@ type Hash interface {
 	// Reset empty the internal state and put the intermediate state to zero.
 	Reset()
 }
+
+// Builder returns the gadget of a hash function, in the constraint system of api.
+type Builder func(api frontend.API) (Hash, error)
+
+var registry = make(map[cryptohash.Hash]Builder)
+var registryM sync.RWMutex
+
+// Register registers the gadget implementing the native (gnark-crypto) hash function id.
+// It is called in the init function of the packages implementing the gadgets, for
+// example std/hash/mimc registers cryptohash.MIMC_BN254 and the other MiMC instances.
+func Register(id cryptohash.Hash, builder Builder) {
+	registryM.Lock()
+	defer registryM.Unlock()
+	if _, ok := registry[id]; ok {
+		log := logger.Logger()
+		log.Warn().Str("name", id.String()).Msg("hash function registered multiple times")
+		return
+	}
+	registry[id] = builder
+}
+
+// New returns the gadget of the native hash function id, so that a circuit and the
+// native code it mirrors can select their hash function from the same identifier.
+// The package implementing the gadget must be imported for it to be registered.
+func New(api frontend.API, id cryptohash.Hash) (Hash, error) {
+	registryM.RLock()
+	builder, ok := registry[id]
+	registryM.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("no gadget registered for hash function %s", id.String())
+	}
+	return builder(api)
+}
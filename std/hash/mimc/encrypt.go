@@ -17,6 +17,8 @@ limitations under the License.
 package mimc
 
 import (
+	"fmt"
+
 	"github.com/consensys/gnark-crypto/ecc"
 	bls12377 "github.com/consensys/gnark-crypto/ecc/bls12-377/fr/mimc"
 	bls12381 "github.com/consensys/gnark-crypto/ecc/bls12-381/fr/mimc"
@@ -24,8 +26,10 @@ import (
 	bn254 "github.com/consensys/gnark-crypto/ecc/bn254/fr/mimc"
 	bw6633 "github.com/consensys/gnark-crypto/ecc/bw6-633/fr/mimc"
 	bw6761 "github.com/consensys/gnark-crypto/ecc/bw6-761/fr/mimc"
+	cryptohash "github.com/consensys/gnark-crypto/hash"
 
 	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/std/hash"
 )
 
 var encryptFuncs map[ecc.ID]func(MiMC, frontend.Variable) frontend.Variable
@@ -47,6 +51,31 @@ func init() {
 	newMimc[ecc.BW6_761] = newMimcBW761
 	newMimc[ecc.BW6_633] = newMimcBW633
 	newMimc[ecc.BLS24_315] = newMimcBLS315
+
+	// native hash functions matching the gadgets
+	hashIDs := map[ecc.ID]cryptohash.Hash{
+		ecc.BN254:     cryptohash.MIMC_BN254,
+		ecc.BLS12_381: cryptohash.MIMC_BLS12_381,
+		ecc.BLS12_377: cryptohash.MIMC_BLS12_377,
+		ecc.BW6_761:   cryptohash.MIMC_BW6_761,
+		ecc.BW6_633:   cryptohash.MIMC_BW6_633,
+		ecc.BLS24_315: cryptohash.MIMC_BLS24_315,
+	}
+	for curve, id := range hashIDs {
+		hash.Register(id, newMimcBuilder(curve, id))
+	}
+}
+
+// newMimcBuilder returns the builder registered for the native hash function id, which is
+// only defined in circuits over the scalar field of curve.
+func newMimcBuilder(curve ecc.ID, id cryptohash.Hash) hash.Builder {
+	return func(api frontend.API) (hash.Hash, error) {
+		if api.Compiler().Curve() != curve {
+			return nil, fmt.Errorf("%s is not defined over the scalar field of %s", id.String(), api.Compiler().Curve().String())
+		}
+		h := newMimc[curve](api)
+		return &h, nil
+	}
 }
 
 // -------------------------------------------------------------------------------------------------
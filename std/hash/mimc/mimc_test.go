@@ -23,6 +23,9 @@ import (
 	"github.com/consensys/gnark-crypto/ecc"
 	"github.com/consensys/gnark-crypto/hash"
 	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/frontend/cs/r1cs"
+	"github.com/consensys/gnark/internal/stdtest"
+	stdhash "github.com/consensys/gnark/std/hash"
 	"github.com/consensys/gnark/test"
 )
 
@@ -89,3 +92,63 @@ func TestMimcAll(t *testing.T) {
 	}
 
 }
+
+type registryCircuit struct {
+	id             hash.Hash
+	ExpectedResult frontend.Variable `gnark:"data,public"`
+	Data           frontend.Variable
+}
+
+func (circuit *registryCircuit) Define(api frontend.API) error {
+	h, err := stdhash.New(api, circuit.id)
+	if err != nil {
+		return err
+	}
+	h.Write(circuit.Data)
+	api.AssertIsEqual(h.Sum(), circuit.ExpectedResult)
+	return nil
+}
+
+func TestRegistry(t *testing.T) {
+	assert := test.NewAssert(t)
+
+	curves := []struct {
+		curve    ecc.ID
+		hashFunc hash.Hash
+	}{
+		{ecc.BN254, hash.MIMC_BN254},
+		{ecc.BLS12_381, hash.MIMC_BLS12_381},
+		{ecc.BLS12_377, hash.MIMC_BLS12_377},
+		{ecc.BW6_761, hash.MIMC_BW6_761},
+		{ecc.BW6_633, hash.MIMC_BW6_633},
+		{ecc.BLS24_315, hash.MIMC_BLS24_315},
+	}
+
+	circuits := stdtest.Circuits(len(curves), func(i int) frontend.Circuit {
+		return &registryCircuit{id: curves[i].hashFunc}
+	})
+
+	for i, c := range curves {
+		curve, hashFunc := c.curve, c.hashFunc
+
+		// the gadget registered for hashFunc matches the native hash function
+		data := big.NewInt(42)
+		goMimc := hashFunc.New()
+		goMimc.Write(data.Bytes())
+
+		witness := registryCircuit{Data: data, ExpectedResult: goMimc.Sum(nil)}
+		assert.SolvingSucceeded(circuits[i], &witness, test.WithCurves(curve))
+
+		// and is not defined on other curves
+		other := ecc.BN254
+		if curve == ecc.BN254 {
+			other = ecc.BLS12_381
+		}
+		_, err := frontend.Compile(other, r1cs.NewBuilder, &registryCircuit{id: hashFunc})
+		assert.Error(err)
+	}
+
+	// unregistered hash function
+	_, err := frontend.Compile(ecc.BN254, r1cs.NewBuilder, &registryCircuit{id: hash.MIMC_BW6_756})
+	assert.Error(err)
+}
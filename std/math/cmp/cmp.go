@@ -0,0 +1,29 @@
+/*
+Copyright © 2020 ConsenSys
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package cmp provides comparison helpers over frontend.Variable.
+//
+// Assertions are part of frontend.API (api.AssertIsEqual, api.AssertIsDifferent, ...); this
+// package provides their boolean counterparts, to be combined with other conditions.
+package cmp
+
+import "github.com/consensys/gnark/frontend"
+
+// IsEqual returns 1 if a == b, 0 otherwise.
+// The result is constrained to be boolean.
+func IsEqual(api frontend.API, a, b frontend.Variable) frontend.Variable {
+	return api.IsZero(api.Sub(a, b))
+}
@@ -0,0 +1,50 @@
+/*
+Copyright © 2020 ConsenSys
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmp_test
+
+import (
+	"testing"
+
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/std/math/cmp"
+	"github.com/consensys/gnark/test"
+)
+
+type isEqualCircuit struct {
+	A, B     frontend.Variable
+	Expected frontend.Variable
+}
+
+func (c *isEqualCircuit) Define(api frontend.API) error {
+	res := cmp.IsEqual(api, c.A, c.B)
+	api.AssertIsEqual(res, c.Expected)
+
+	return nil
+}
+
+func TestIsEqual(t *testing.T) {
+	assert := test.NewAssert(t)
+
+	var circuit isEqualCircuit
+
+	assert.ProverSucceeded(&circuit, &isEqualCircuit{A: 42, B: 42, Expected: 1})
+	assert.ProverSucceeded(&circuit, &isEqualCircuit{A: 42, B: 43, Expected: 0})
+	assert.ProverSucceeded(&circuit, &isEqualCircuit{A: 0, B: 0, Expected: 1})
+
+	assert.ProverFailed(&circuit, &isEqualCircuit{A: 42, B: 42, Expected: 0})
+	assert.ProverFailed(&circuit, &isEqualCircuit{A: 42, B: 43, Expected: 1})
+}
@@ -0,0 +1,48 @@
+/*
+Copyright © 2020 ConsenSys
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package eddsa
+
+import (
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/std/accumulator/merkle"
+	"github.com/consensys/gnark/std/algebra/twistededwards"
+	"github.com/consensys/gnark/std/hash/mimc"
+)
+
+// AuthorizeTx asserts that pubKey is registered in the Merkle tree of root merkleRoot, and
+// that sig is a valid signature of msg under pubKey.
+//
+// The leaf registering pubKey must be MiMC(A.X, A.Y): proofSet[0] and helper are the
+// Merkle proof of that leaf, as expected by merkle.VerifyProof. Circuits with richer
+// leaves (e.g. accounts holding a balance and a nonce) should chain merkle.VerifyProof and
+// Verify themselves, as done in examples/rollup.
+func AuthorizeTx(curve twistededwards.Curve, h mimc.MiMC, merkleRoot frontend.Variable, proofSet, helper []frontend.Variable, sig Signature, msg frontend.Variable, pubKey PublicKey, opts ...VerifyOption) error {
+	api := curve.API()
+
+	// the leaf commits to the public key
+	h.Reset()
+	h.Write(pubKey.A.X, pubKey.A.Y)
+	api.AssertIsEqual(proofSet[0], h.Sum())
+
+	// the leaf is in the tree
+	h.Reset()
+	merkle.VerifyProof(api, h, merkleRoot, proofSet, helper)
+
+	// the transaction is signed by the owner of the leaf
+	h.Reset()
+	return Verify(curve, sig, msg, pubKey, &h, opts...)
+}
@@ -0,0 +1,132 @@
+/*
+Copyright © 2020 ConsenSys
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package eddsa
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/accumulator/merkletree"
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark-crypto/ecc/bn254/fr"
+	edbn254 "github.com/consensys/gnark-crypto/ecc/bn254/twistededwards"
+	tedwards "github.com/consensys/gnark-crypto/ecc/twistededwards"
+	"github.com/consensys/gnark-crypto/hash"
+	"github.com/consensys/gnark-crypto/signature/eddsa"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/std/accumulator/merkle"
+	"github.com/consensys/gnark/std/algebra/twistededwards"
+	"github.com/consensys/gnark/std/hash/mimc"
+	"github.com/consensys/gnark/std/signature/eddsa/testutil"
+	"github.com/consensys/gnark/test"
+)
+
+type authorizeTxCircuit struct {
+	RootHash  frontend.Variable `gnark:",public"`
+	Path      []frontend.Variable
+	Helper    []frontend.Variable
+	PublicKey PublicKey
+	Signature Signature
+	Message   frontend.Variable `gnark:",public"`
+}
+
+func (circuit *authorizeTxCircuit) Define(api frontend.API) error {
+	curve, err := twistededwards.NewEdCurve(api, tedwards.BN254)
+	if err != nil {
+		return err
+	}
+	h, err := mimc.NewMiMC(api)
+	if err != nil {
+		return err
+	}
+	return AuthorizeTx(curve, h, circuit.RootHash, circuit.Path, circuit.Helper, circuit.Signature, circuit.Message, circuit.PublicKey)
+}
+
+func TestAuthorizeTx(t *testing.T) {
+	assert := test.NewAssert(t)
+
+	const (
+		nbLeaves   = 4
+		proofIndex = 2
+	)
+
+	seed := testutil.SeedFromString("TestAuthorizeTx")
+	privKey, err := eddsa.New(tedwards.BN254, bytes.NewReader(seed[:]))
+	assert.NoError(err)
+
+	var A edbn254.PointAffine
+	_, err = A.SetBytes(privKey.Public().Bytes())
+	assert.NoError(err)
+
+	// the registered leaf is MiMC(A.X, A.Y), the other leaves are random
+	var buf bytes.Buffer
+	for i := 0; i < nbLeaves; i++ {
+		if i == proofIndex {
+			ax, ay := A.X.Bytes(), A.Y.Bytes()
+			h := hash.MIMC_BN254.New()
+			h.Write(ax[:])
+			h.Write(ay[:])
+			buf.Write(h.Sum(nil))
+			continue
+		}
+		var leaf fr.Element
+		_, err := leaf.SetRandom()
+		assert.NoError(err)
+		b := leaf.Bytes()
+		buf.Write(b[:])
+	}
+	root, proof, numLeaves, err := merkletree.BuildReaderProof(&buf, hash.MIMC_BN254.New(), fr.Bytes, proofIndex)
+	assert.NoError(err)
+	helper := merkle.GenerateProofHelper(proof, proofIndex, numLeaves)
+
+	var msg fr.Element
+	msg.SetUint64(42)
+	mb := msg.Bytes()
+	sig, err := privKey.Sign(mb[:], hash.MIMC_BN254.New())
+	assert.NoError(err)
+
+	circuit := authorizeTxCircuit{
+		Path:   make([]frontend.Variable, len(proof)),
+		Helper: make([]frontend.Variable, len(proof)-1),
+	}
+	witness := authorizeTxCircuit{
+		RootHash: root,
+		Path:     make([]frontend.Variable, len(proof)),
+		Helper:   make([]frontend.Variable, len(proof)-1),
+		Message:  msg,
+	}
+	for i := range proof {
+		witness.Path[i] = proof[i]
+	}
+	for i := range helper {
+		witness.Helper[i] = helper[i]
+	}
+	witness.PublicKey.Assign(ecc.BN254, privKey.Public().Bytes())
+	witness.Signature.Assign(ecc.BN254, sig)
+
+	assert.ProverSucceeded(&circuit, &witness, test.WithCurves(ecc.BN254))
+
+	// a signature by a key which is not registered
+	seed = testutil.SeedFromString("TestAuthorizeTx/unregistered")
+	other, err := eddsa.New(tedwards.BN254, bytes.NewReader(seed[:]))
+	assert.NoError(err)
+	sig, err = other.Sign(mb[:], hash.MIMC_BN254.New())
+	assert.NoError(err)
+	witness.PublicKey.Assign(ecc.BN254, other.Public().Bytes())
+	witness.Signature.Assign(ecc.BN254, sig)
+	assert.ProverFailed(&circuit, &witness, test.WithCurves(ecc.BN254))
+}
@@ -19,13 +19,17 @@ package eddsa
 
 import (
 	"errors"
+	"fmt"
+	"math/big"
 
 	"github.com/consensys/gnark-crypto/ecc"
+	cryptohash "github.com/consensys/gnark-crypto/hash"
 	"github.com/consensys/gnark/logger"
 	"github.com/consensys/gnark/std/hash"
 
 	"github.com/consensys/gnark/frontend"
 	"github.com/consensys/gnark/std/algebra/twistededwards"
+	"github.com/consensys/gnark/std/math/cmp"
 
 	edwardsbls12377 "github.com/consensys/gnark-crypto/ecc/bls12-377/twistededwards"
 	edwardsbls12381 "github.com/consensys/gnark-crypto/ecc/bls12-381/twistededwards"
@@ -35,7 +39,17 @@ import (
 	edwardsbw6761 "github.com/consensys/gnark-crypto/ecc/bw6-761/twistededwards"
 )
 
+// ErrNilHash is returned by Verify when no hash function is provided
+var ErrNilHash = errors.New("eddsa: nil hash function")
+
+// ErrSignatureMalleable is returned by AssignSignature when S is not reduced modulo the
+// order of the subgroup. Without WithNonMalleable, such a signature still verifies.
+var ErrSignatureMalleable = errors.New("eddsa: signature scalar not in [0, order)")
+
 // PublicKey stores an eddsa public key (to be used in gnark circuit)
+//
+// Witness leaves are laid out in declaration order: a PublicKey contributes
+// A.X then A.Y to the (public) witness vector.
 type PublicKey struct {
 	A twistededwards.Point
 }
@@ -46,57 +60,319 @@ type PublicKey struct {
 // N on the Edwards is < r+1+2sqrt(r)+2 (since the curve has 2 points of multiplicity 2).
 // The subgroup l used in eddsa is <1/2N, so the reduction
 // mod l ensures S < r, therefore there is no risk of overflow.
+//
+// Witness leaves are laid out in declaration order: a Signature contributes
+// R.X, R.Y then S to the (public) witness vector.
 type Signature struct {
 	R twistededwards.Point
 	S frontend.Variable
 }
 
+// ChallengeTerm identifies a value absorbed by the hash when computing the challenge H(R, A, M).
+type ChallengeTerm uint8
+
+const (
+	TermRX ChallengeTerm = iota // x coordinate of R
+	TermRY                      // y coordinate of R
+	TermAX                      // x coordinate of the public key A
+	TermAY                      // y coordinate of the public key A
+	TermM                       // message
+)
+
+// defaultChallengeOrder is the absorb order of the challenge, H(Rx, Ry, Ax, Ay, M).
+var defaultChallengeOrder = [...]ChallengeTerm{TermRX, TermRY, TermAX, TermAY, TermM}
+
+// DefaultChallengeOrder returns a copy of the default absorb order of the challenge,
+// H(Rx, Ry, Ax, Ay, M). It matches the native eddsa implementations of gnark-crypto.
+func DefaultChallengeOrder() []ChallengeTerm {
+	return append([]ChallengeTerm{}, defaultChallengeOrder[:]...)
+}
+
+type verifyConfig struct {
+	challengeOrder []ChallengeTerm
+	nonMalleable   bool
+	rejectWeakKeys bool
+	associatedData []frontend.Variable
+	prefix         []frontend.Variable
+}
+
+// VerifyOption configures the behaviour of the signature verification.
+type VerifyOption func(opt *verifyConfig) error
+
+// WithChallengeOrder sets the order in which R, A and M are absorbed by the hash
+// when computing the challenge, to match external (non gnark) signers.
+// For example WithChallengeOrder(TermRX, TermRY, TermM) computes H(R, M).
+//
+// Each term must appear at most once, and R and M must be absorbed.
+// Signatures produced by gnark-crypto only verify with DefaultChallengeOrder.
+func WithChallengeOrder(terms ...ChallengeTerm) VerifyOption {
+	return func(opt *verifyConfig) error {
+		var seen [TermM + 1]bool
+		for _, t := range terms {
+			if t > TermM {
+				return errors.New("unknown challenge term")
+			}
+			if seen[t] {
+				return errors.New("challenge term absorbed more than once")
+			}
+			seen[t] = true
+		}
+		if !seen[TermRX] || !seen[TermRY] || !seen[TermM] {
+			return errors.New("challenge must absorb R and M")
+		}
+		opt.challengeOrder = append([]ChallengeTerm{}, terms...)
+		return nil
+	}
+}
+
+// WithNonMalleable rejects the malleable forms of a valid signature: it asserts that
+// S is in [0, order) and that the public key is in the prime order subgroup
+// (see AssertPublicKeyInSubgroup).
+// Without it, (R, S + order) verifies whenever (R, S) does, and so does a signature
+// under A + T for a small order point T.
+// Natively, AssignSignature rejects the first form with ErrSignatureMalleable.
+//
+// It is not set by default to keep the constraint count of existing circuits, but is
+// recommended whenever the signature itself (and not only the signed message) matters,
+// for instance when it is used as a nullifier.
+func WithNonMalleable() VerifyOption {
+	return func(opt *verifyConfig) error {
+		opt.nonMalleable = true
+		return nil
+	}
+}
+
+// WithRejectWeakKeys rejects the public keys of small order: the identity (0, 1) and the
+// other points of order dividing the cofactor (for a cofactor 8: (0, -1), the two points
+// of order 4 (±sqrt(1/a), 0) and the four points of order 8). For such a key, (R, S) with
+// R = [S]G verifies for any message.
+//
+// It costs as many doublings as the cofactor bit size, much less than WithNonMalleable,
+// which already rejects all of them but the identity.
+func WithRejectWeakKeys() VerifyOption {
+	return func(opt *verifyConfig) error {
+		opt.rejectWeakKeys = true
+		return nil
+	}
+}
+
+// WithAssociatedData binds context (timestamps, recipient identifiers...) to the signature
+// without making it part of the message: the challenge absorbs aad, in order, right before
+// M, that is H(Rx, Ry, Ax, Ay, aad[0], ..., aad[n-1], M) with the default order.
+//
+// Natively, this is a gnark-crypto signature of the concatenation of the fixed size
+// (fr.Bytes) big endian encodings of aad[0], ..., aad[n-1] and of the message. An empty aad
+// doesn't change the challenge.
+func WithAssociatedData(aad ...frontend.Variable) VerifyOption {
+	return func(opt *verifyConfig) error {
+		opt.associatedData = aad
+		return nil
+	}
+}
+
+// WithChallengePrefix binds a caller-provided context (for instance a session ID) to the
+// signature: the challenge absorbs prefix, in order, before R, A and M, that is
+// H(prefix[0], ..., prefix[n-1], Rx, Ry, Ax, Ay, M) with the default order.
+//
+// The prefix must match exactly, element by element, the one used by the signer; an empty
+// prefix doesn't change the challenge. The native eddsa of gnark-crypto always absorbs R
+// first, so such signatures must be produced by an external signer, writing the fixed size
+// (fr.Bytes) big endian encodings of the prefix in the hash before those of R.
+func WithChallengePrefix(prefix ...frontend.Variable) VerifyOption {
+	return func(opt *verifyConfig) error {
+		opt.prefix = append([]frontend.Variable{}, prefix...)
+		return nil
+	}
+}
+
 // Verify verifies an eddsa signature using MiMC hash function
 // cf https://en.wikipedia.org/wiki/EdDSA
-func Verify(curve twistededwards.Curve, sig Signature, msg frontend.Variable, pubKey PublicKey, hash hash.Hash) error {
+//
+// The challenge is H(Rx, Ry, Ax, Ay, M) unless specified otherwise with WithChallengeOrder
+// or WithChallengePrefix.
+// The message is always absorbed: msg = 0 matches a native signature of the zero
+// field element, not a native signature of an empty message (H(Rx, Ry, Ax, Ay)).
+//
+// The base point, order and cofactor are taken from curve.Params(); a curve built with
+// twistededwards.NewEdCurveWithParams verifies against explicit parameters instead.
+//
+// The constraint count of Verify on BN254 with MiMC is tracked in internal/stats, as the
+// signature/eddsa and signature/eddsa/NonMalleable snippets.
+func Verify(curve twistededwards.Curve, sig Signature, msg frontend.Variable, pubKey PublicKey, hash hash.Hash, opts ...VerifyOption) error {
+	Q, err := verificationPoint(curve, sig, msg, pubKey, hash, opts...)
+	if err != nil {
+		return err
+	}
 
-	// compute H(R, A, M)
-	hash.Write(sig.R.X)
-	hash.Write(sig.R.Y)
-	hash.Write(pubKey.A.X)
-	hash.Write(pubKey.A.Y)
-	hash.Write(msg)
-	hRAM := hash.Sum()
+	curve.API().AssertIsEqual(Q.X, 0)
+	curve.API().AssertIsEqual(Q.Y, 1)
+
+	return nil
+}
+
+// VerifyWithHashID verifies an eddsa signature as Verify does, using the gadget registered in
+// std/hash for the native (gnark-crypto) hash function id. A signature produced natively with
+// id.New() verifies with the same id, and an id whose gadget doesn't match the snark field of
+// curve is rejected, so that the native and in-circuit hash functions can't differ.
+//
+// The package implementing the gadget must be imported, for instance std/hash/mimc for the
+// MiMC instances. Verify still takes the gadget itself, which can then be reset and reused
+// across several verifications.
+func VerifyWithHashID(curve twistededwards.Curve, sig Signature, msg frontend.Variable, pubKey PublicKey, id cryptohash.Hash, opts ...VerifyOption) error {
+	h, err := hash.New(curve.API(), id)
+	if err != nil {
+		return err
+	}
+	return Verify(curve, sig, msg, pubKey, h, opts...)
+}
+
+// VerifyPoint verifies an eddsa signature of a point of the curve. The coordinates of msg
+// are absorbed as the message, X then Y: the challenge is H(Rx, Ry, Ax, Ay, Px, Py) with the
+// default order and no associated data.
+//
+// Natively, this is a gnark-crypto signature of the concatenation of the fixed size
+// (fr.Bytes) big endian encodings of P.X and P.Y, not of the compressed point. msg is not
+// required to be on the curve.
+func VerifyPoint(curve twistededwards.Curve, sig Signature, msg twistededwards.Point, pubKey PublicKey, hash hash.Hash, opts ...VerifyOption) error {
+	return Verify(curve, sig, msg.Y, pubKey, hash, append(append([]VerifyOption{}, opts...), withMessagePrefix(msg.X))...)
+}
+
+// withMessagePrefix absorbs prefix after the associated data, right before the message, for
+// messages spanning several field elements.
+func withMessagePrefix(prefix ...frontend.Variable) VerifyOption {
+	return func(opt *verifyConfig) error {
+		opt.associatedData = append(append([]frontend.Variable{}, opt.associatedData...), prefix...)
+		return nil
+	}
+}
+
+// IsValid returns 1 if sig is a valid signature of msg under pubKey, 0 otherwise, so that
+// signature validity can be combined with other conditions (e.g. api.Or(valid, isAdmin)).
+//
+// It accepts the same options as Verify. The public key and R must still be on the curve,
+// and the checks enabled by WithNonMalleable and WithRejectWeakKeys are still asserted: only
+// the verification equation is turned into a boolean.
+func IsValid(curve twistededwards.Curve, sig Signature, msg frontend.Variable, pubKey PublicKey, hash hash.Hash, opts ...VerifyOption) (frontend.Variable, error) {
+	Q, err := verificationPoint(curve, sig, msg, pubKey, hash, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	return isIdentity(curve.API(), Q), nil
+}
+
+// isIdentity returns 1 if P is the identity (0, 1), 0 otherwise.
+func isIdentity(api frontend.API, P twistededwards.Point) frontend.Variable {
+	return api.And(cmp.IsEqual(api, P.X, 0), cmp.IsEqual(api, P.Y, 1))
+}
+
+// verificationPoint returns [cofactor]([S]G-[H(R,A,M)]*A-R), which is the identity (0, 1)
+// if and only if the signature is valid.
+func verificationPoint(curve twistededwards.Curve, sig Signature, msg frontend.Variable, pubKey PublicKey, hash hash.Hash, opts ...VerifyOption) (twistededwards.Point, error) {
+
+	if hash == nil {
+		return twistededwards.Point{}, ErrNilHash
+	}
+
+	cfg := verifyConfig{challengeOrder: defaultChallengeOrder[:]}
+	for _, o := range opts {
+		if err := o(&cfg); err != nil {
+			return twistededwards.Point{}, err
+		}
+	}
 
-	base := twistededwards.Point{
-		X: curve.Params().Base[0],
-		Y: curve.Params().Base[1],
+	// ensure the public key is a valid point
+	AssertValidPublicKey(curve, pubKey)
+
+	// the addition formulas are not complete off the curve: an invalid R could make them divide
+	// by zero instead of yielding a point other than the identity
+	curve.AssertIsOnCurve(sig.R)
+
+	if cfg.nonMalleable {
+		twistededwards.AssertScalarInRange(curve, sig.S)
+		AssertPublicKeyInSubgroup(curve, pubKey)
 	}
 
+	if cfg.rejectWeakKeys {
+		// A is of small order iff [cofactor]A = (0, 1)
+		T, err := clearCofactor(curve, pubKey.A)
+		if err != nil {
+			return twistededwards.Point{}, err
+		}
+		curve.API().AssertIsEqual(isIdentity(curve.API(), T), 0)
+	}
+
+	// compute H(R, A, M)
+	hRAM := challenge(sig, msg, pubKey, hash, cfg.challengeOrder, cfg.prefix, cfg.associatedData)
+
 	//[S]G-[H(R,A,M)]*A
 	_A := curve.Neg(pubKey.A)
-	Q := curve.DoubleBaseScalarMul(base, _A, sig.S, hRAM)
+	Q := curve.Add(twistededwards.ScalarMulBase(curve, sig.S), curve.ScalarMul(_A, hRAM))
 	curve.AssertIsOnCurve(Q)
 
 	//[S]G-[H(R,A,M)]*A-R
 	Q = curve.Add(curve.Neg(Q), sig.R)
 
 	// [cofactor]*(lhs-rhs)
+	return clearCofactor(curve, Q)
+}
+
+// clearCofactor returns [cofactor]P.
+func clearCofactor(curve twistededwards.Curve, P twistededwards.Point) (twistededwards.Point, error) {
 	log := logger.Logger()
 	if !curve.Params().Cofactor.IsUint64() {
 		err := errors.New("invalid cofactor")
 		log.Err(err).Str("cofactor", curve.Params().Cofactor.String()).Send()
-		return err
+		return twistededwards.Point{}, err
 	}
 	cofactor := curve.Params().Cofactor.Uint64()
 	switch cofactor {
+	case 1:
+		// prime order curve, nothing to clear
 	case 4:
-		Q = curve.Double(curve.Double(Q))
+		P = curve.Double(curve.Double(P))
 	case 8:
-		Q = curve.Double(curve.Double(curve.Double(Q)))
+		P = curve.Double(curve.Double(curve.Double(P)))
 	default:
 		log.Warn().Str("cofactor", curve.Params().Cofactor.String()).Msg("curve cofactor is not implemented")
 	}
 
+	return P, nil
+}
+
+// challenge computes H(R, A, M), absorbing prefix first, then the terms in the given order,
+// and the associated data aad right before M.
+//
+// Each term is absorbed as one field element. With the default order, this matches the
+// native eddsa of gnark-crypto, which writes in the hash the big endian, fixed size
+// (fr.Bytes) encodings of Rx, Ry, Ax, Ay, followed by the message bytes.
+func challenge(sig Signature, msg frontend.Variable, pubKey PublicKey, hash hash.Hash, order []ChallengeTerm, prefix, aad []frontend.Variable) frontend.Variable {
+	terms := [...]frontend.Variable{sig.R.X, sig.R.Y, pubKey.A.X, pubKey.A.Y, msg}
+	hash.Write(prefix...)
+	for _, t := range order {
+		if t == TermM {
+			hash.Write(aad...)
+		}
+		hash.Write(terms[t])
+	}
+	return hash.Sum()
+}
+
+// AssertValidPublicKey asserts that the public key is a point on the twisted Edwards curve.
+// It is called by Verify; it doesn't ensure the public key is in the prime order subgroup,
+// see AssertPublicKeyInSubgroup.
+func AssertValidPublicKey(curve twistededwards.Curve, pubKey PublicKey) {
+	curve.AssertIsOnCurve(pubKey.A)
+}
+
+// AssertPublicKeyInSubgroup asserts that the public key is in the prime order subgroup
+// of the twisted Edwards curve, that is [order]A = (0, 1).
+// It costs a scalar multiplication and is not called by Verify, where the cofactor is
+// cleared instead.
+func AssertPublicKeyInSubgroup(curve twistededwards.Curve, pubKey PublicKey) {
+	Q := curve.ScalarMul(pubKey.A, curve.Params().Order)
 	curve.API().AssertIsEqual(Q.X, 0)
 	curve.API().AssertIsEqual(Q.Y, 1)
-
-	return nil
 }
 
 // Assign is a helper to assigned a compressed binary public key representation into its uncompressed form
@@ -120,6 +396,89 @@ func (s *Signature) Assign(curveID ecc.ID, buf []byte) {
 	s.S = S
 }
 
+// AssignSignature is a helper to build the inputs of Verify from their native (gnark-crypto) counterparts:
+// pubKey is the compressed binary public key, sig the compressed binary signature and msg the signed message.
+//
+// Verify absorbs the message as a single variable, so msg must fit in a field element of curveID.
+// A signature whose S is not in [0, order) is rejected with ErrSignatureMalleable: gnark-crypto
+// never produces one, and it is the malleable form (R, S + order) of a valid signature.
+func AssignSignature(curveID ecc.ID, pubKey, sig, msg []byte) (PublicKey, Signature, frontend.Variable, error) {
+	var p PublicKey
+	var s Signature
+
+	sizeFr, err := frSize(curveID)
+	if err != nil {
+		return p, s, nil, err
+	}
+	if len(pubKey) != sizeFr {
+		return p, s, nil, errors.New("invalid public key size")
+	}
+	if len(sig) != 2*sizeFr {
+		return p, s, nil, errors.New("invalid signature size")
+	}
+	if len(msg) > sizeFr {
+		return p, s, nil, errors.New("message doesn't fit in a field element")
+	}
+
+	ax, ay, err := parsePoint(curveID, pubKey)
+	if err != nil {
+		return p, s, nil, err
+	}
+	rx, ry, S, err := parseSignature(curveID, sig)
+	if err != nil {
+		return p, s, nil, err
+	}
+
+	if new(big.Int).SetBytes(S).Cmp(subgroupOrder(curveID)) >= 0 {
+		return p, s, nil, ErrSignatureMalleable
+	}
+
+	p.A.X, p.A.Y = ax, ay
+	s.R.X, s.R.Y, s.S = rx, ry, S
+
+	return p, s, msg, nil
+}
+
+// PublicInputs returns the public inputs of a circuit whose public fields are, in declaration
+// order, a PublicKey and the signed message, the signature being secret:
+//
+//	type Circuit struct {
+//		PublicKey eddsa.PublicKey   `gnark:",public"`
+//		Signature eddsa.Signature
+//		Message   frontend.Variable `gnark:",public"`
+//	}
+//
+// The result is A.X, A.Y, M, which is the order of the public witness, and of the public
+// inputs of the groth16 verifier (for instance the exported Solidity contract) of such a
+// circuit. pubKey is the compressed binary public key and msg the signed message; as in the
+// witness, msg is read as a big endian integer and reduced modulo the scalar field of curveID.
+func PublicInputs(curveID ecc.ID, pubKey, msg []byte) ([]*big.Int, error) {
+	sizeFr, err := frSize(curveID)
+	if err != nil {
+		return nil, err
+	}
+	if len(pubKey) != sizeFr {
+		return nil, errors.New("invalid public key size")
+	}
+	if len(msg) > sizeFr {
+		return nil, errors.New("message doesn't fit in a field element")
+	}
+
+	ax, ay, err := parsePoint(curveID, pubKey)
+	if err != nil {
+		return nil, err
+	}
+
+	m := new(big.Int).SetBytes(msg)
+	m.Mod(m, curveID.Info().Fr.Modulus())
+
+	return []*big.Int{
+		new(big.Int).SetBytes(ax),
+		new(big.Int).SetBytes(ay),
+		m,
+	}, nil
+}
+
 // parseSignature parses a compressed binary signature into uncompressed R.X, R.Y and S
 func parseSignature(curveID ecc.ID, buf []byte) ([]byte, []byte, []byte, error) {
 
@@ -192,7 +551,7 @@ func parseSignature(curveID ecc.ID, buf []byte) ([]byte, []byte, []byte, error)
 		s := buf[40:]
 		return a, b, s, nil
 	default:
-		panic("not implemented")
+		return nil, nil, nil, errUnsupportedCurve(curveID)
 	}
 }
 
@@ -248,6 +607,48 @@ func parsePoint(curveID ecc.ID, buf []byte) ([]byte, []byte, error) {
 		b := pointbw6633.Y.Bytes()
 		return a[:], b[:], nil
 	default:
-		panic("not implemented")
+		return nil, nil, errUnsupportedCurve(curveID)
 	}
 }
+
+// frSize returns the size in bytes of the scalar field of curveID, or an error if its
+// companion twisted Edwards curve is not supported.
+func frSize(curveID ecc.ID) (int, error) {
+	switch curveID {
+	case ecc.BN254, ecc.BLS12_381, ecc.BLS12_377, ecc.BW6_761, ecc.BLS24_315, ecc.BW6_633:
+		return curveID.Info().Fr.Bytes, nil
+	default:
+		return 0, errUnsupportedCurve(curveID)
+	}
+}
+
+// subgroupOrder returns the order of the prime subgroup of the twisted Edwards companion of
+// curveID, which must be supported.
+func subgroupOrder(curveID ecc.ID) *big.Int {
+	switch curveID {
+	case ecc.BN254:
+		c := edwardsbn254.GetEdwardsCurve()
+		return &c.Order
+	case ecc.BLS12_381:
+		c := edwardsbls12381.GetEdwardsCurve()
+		return &c.Order
+	case ecc.BLS12_377:
+		c := edwardsbls12377.GetEdwardsCurve()
+		return &c.Order
+	case ecc.BW6_761:
+		c := edwardsbw6761.GetEdwardsCurve()
+		return &c.Order
+	case ecc.BLS24_315:
+		c := edwardsbls24315.GetEdwardsCurve()
+		return &c.Order
+	case ecc.BW6_633:
+		c := edwardsbw6633.GetEdwardsCurve()
+		return &c.Order
+	default:
+		panic(errUnsupportedCurve(curveID))
+	}
+}
+
+func errUnsupportedCurve(curveID ecc.ID) error {
+	return fmt.Errorf("eddsa: unsupported curve %s", curveID)
+}
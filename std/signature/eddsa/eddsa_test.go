@@ -17,17 +17,28 @@ limitations under the License.
 package eddsa
 
 import (
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"fmt"
 	"math/big"
 	"math/rand"
 	"testing"
 	"time"
 
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark-crypto/ecc/bn254/fr"
+	edbn254 "github.com/consensys/gnark-crypto/ecc/bn254/twistededwards"
 	tedwards "github.com/consensys/gnark-crypto/ecc/twistededwards"
 	"github.com/consensys/gnark-crypto/hash"
+	"github.com/consensys/gnark-crypto/signature"
 	"github.com/consensys/gnark-crypto/signature/eddsa"
 	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/frontend/cs/r1cs"
+	"github.com/consensys/gnark/internal/stdtest"
 	"github.com/consensys/gnark/std/algebra/twistededwards"
 	"github.com/consensys/gnark/std/hash/mimc"
+	"github.com/consensys/gnark/std/signature/eddsa/testutil"
 	"github.com/consensys/gnark/test"
 )
 
@@ -138,3 +149,1025 @@ func TestEddsa(t *testing.T) {
 	}
 
 }
+
+type hashIDCircuit struct {
+	curveID   tedwards.ID
+	hashID    hash.Hash
+	PublicKey PublicKey         `gnark:",public"`
+	Signature Signature         `gnark:",public"`
+	Message   frontend.Variable `gnark:",public"`
+}
+
+func (circuit *hashIDCircuit) Define(api frontend.API) error {
+	curve, err := twistededwards.NewEdCurve(api, circuit.curveID)
+	if err != nil {
+		return err
+	}
+	return VerifyWithHashID(curve, circuit.Signature, circuit.Message, circuit.PublicKey, circuit.hashID)
+}
+
+// TestVerifyWithHashID signs natively with id.New() and verifies in-circuit with the gadget
+// registered for id.
+func TestVerifyWithHashID(t *testing.T) {
+	assert := test.NewAssert(t)
+
+	confs := []struct {
+		hash  hash.Hash
+		curve tedwards.ID
+	}{
+		{hash.MIMC_BN254, tedwards.BN254},
+		{hash.MIMC_BLS12_381, tedwards.BLS12_381},
+		{hash.MIMC_BLS12_377, tedwards.BLS12_377},
+		{hash.MIMC_BW6_761, tedwards.BW6_761},
+		{hash.MIMC_BLS24_315, tedwards.BLS24_315},
+		{hash.MIMC_BW6_633, tedwards.BW6_633},
+	}
+
+	circuits := stdtest.Circuits(len(confs), func(i int) frontend.Circuit {
+		return &hashIDCircuit{curveID: confs[i].curve, hashID: confs[i].hash}
+	})
+	for i, conf := range confs {
+		snarkCurve, err := twistededwards.GetSnarkCurve(conf.curve)
+		assert.NoError(err)
+
+		seed := testutil.SeedFromString("TestVerifyWithHashID")
+		privKey, err := eddsa.New(conf.curve, bytes.NewReader(seed[:]))
+		assert.NoError(err)
+
+		msg := []byte("gnark")
+		sig, err := privKey.Sign(msg, conf.hash.New())
+		assert.NoError(err)
+
+		var witness hashIDCircuit
+		witness.PublicKey.Assign(snarkCurve, privKey.Public().Bytes())
+		witness.Signature.Assign(snarkCurve, sig)
+		witness.Message = msg
+		assert.SolvingSucceeded(circuits[i], &witness, test.WithCurves(snarkCurve))
+
+		// the gadget of another curve's hash function doesn't compile on this one
+		other := confs[(i+1)%len(confs)].hash
+		_, err = frontend.Compile(snarkCurve, r1cs.NewBuilder, &hashIDCircuit{curveID: conf.curve, hashID: other})
+		assert.Error(err, "%s should be rejected on %s", other.String(), snarkCurve.String())
+	}
+
+	// no gadget is registered for MIMC_BLS12_378
+	_, err := frontend.Compile(ecc.BN254, r1cs.NewBuilder, &hashIDCircuit{curveID: tedwards.BN254, hashID: hash.MIMC_BLS12_378})
+	assert.Error(err)
+}
+
+// TestEddsaVectors verifies in-circuit the signatures of the sample test vectors.
+func TestEddsaVectors(t *testing.T) {
+	assert := test.NewAssert(t)
+
+	circuit := eddsaCircuit{curveID: tedwards.BN254}
+	for _, v := range testutil.BN254MiMCVectors() {
+		privKey, err := eddsa.New(tedwards.BN254, bytes.NewReader(v.Seed))
+		assert.NoError(err)
+
+		var witness eddsaCircuit
+		witness.PublicKey.Assign(ecc.BN254, privKey.Public().Bytes())
+		witness.Signature.Assign(ecc.BN254, v.Signature)
+		witness.Message = v.Message
+		assert.SolvingSucceeded(&circuit, &witness, test.WithCurves(ecc.BN254))
+	}
+}
+
+type publicKeyKATCircuit struct {
+	curveID   tedwards.ID
+	Scalar    frontend.Variable
+	PublicKey PublicKey `gnark:",public"`
+}
+
+func (circuit *publicKeyKATCircuit) Define(api frontend.API) error {
+	curve, err := twistededwards.NewEdCurve(api, circuit.curveID)
+	if err != nil {
+		return err
+	}
+	A := twistededwards.ScalarMulBase(curve, circuit.Scalar)
+	api.AssertIsEqual(A.X, circuit.PublicKey.A.X)
+	api.AssertIsEqual(A.Y, circuit.PublicKey.A.Y)
+	return nil
+}
+
+// TestPublicKeyKAT checks the public keys derived from a fixed seed against known answers,
+// and against the base point used in-circuit, to catch miswired curve constants.
+func TestPublicKeyKAT(t *testing.T) {
+	assert := test.NewAssert(t)
+
+	kats := []struct {
+		curveID   tedwards.ID
+		publicKey string
+	}{
+		{tedwards.BN254, "54a7c1977ceafd787528ec8191ea5a078015958e8be24d44ea9f269681c53814"},
+		{tedwards.BLS12_381, "2c838be7652def4fb6e4041030c5a5baf5840910d549a09f0efd3c43ccaabd64"},
+		{tedwards.BLS12_377, "86a83529320b232b6488bc2c4caa30f1d1fb7c65f2415ecf1763f5bc9967b90e"},
+		{tedwards.BW6_761, "cddc9d60dedab3805ddd60b7e3db47f152a93f6a52a58d90e559f23d70a5c69e5a0aed6ed3d399655a386d4f43bd9b01"},
+		{tedwards.BW6_633, "2d29e8cb9c0aea4bff950e0f6158541c6f1d3df675f038b1fb6dfb7b9a762c4f9c93f4a19ea4c500"},
+		{tedwards.BLS24_315, "c983652811e7bdcfa0e090740e199a9f7638dfd0ad6efd7cc2324309d85eb40b"},
+		// BLS12_381_BANDERSNATCH is not listed: in gnark-crypto v0.7.0, its eddsa package is
+		// built on the jubjub curve of BLS12_381, and its keys are not multiples of the
+		// bandersnatch base point.
+	}
+
+	circuits := stdtest.Circuits(len(kats), func(i int) frontend.Circuit {
+		return &publicKeyKATCircuit{curveID: kats[i].curveID}
+	})
+	for i, kat := range kats {
+		seed := testutil.SeedFromString("kat")
+		privKey, err := eddsa.New(kat.curveID, bytes.NewReader(seed[:]))
+		assert.NoError(err)
+
+		pubKey := privKey.Public().Bytes()
+		assert.Equal(kat.publicKey, hex.EncodeToString(pubKey), "curve %d", kat.curveID)
+
+		// the private key is pub || scalar || randSrc, the scalar may exceed the snark field
+		params, err := twistededwards.GetCurveParams(kat.curveID)
+		assert.NoError(err)
+		var scalar big.Int
+		scalar.SetBytes(privKey.Bytes()[len(pubKey) : 2*len(pubKey)])
+		scalar.Mod(&scalar, params.Order)
+
+		snarkCurve, err := twistededwards.GetSnarkCurve(kat.curveID)
+		assert.NoError(err)
+
+		var witness publicKeyKATCircuit
+		witness.Scalar = scalar
+		witness.PublicKey.Assign(snarkCurve, pubKey)
+		assert.SolvingSucceeded(circuits[i], &witness, test.WithCurves(snarkCurve))
+	}
+}
+
+type nilHashCircuit struct {
+	PublicKey PublicKey
+	Signature Signature
+	Message   frontend.Variable
+}
+
+func (circuit *nilHashCircuit) Define(api frontend.API) error {
+	curve, err := twistededwards.NewEdCurve(api, tedwards.BN254)
+	if err != nil {
+		return err
+	}
+	return Verify(curve, circuit.Signature, circuit.Message, circuit.PublicKey, nil)
+}
+
+func TestEddsaNilHash(t *testing.T) {
+	assert := test.NewAssert(t)
+
+	_, err := frontend.Compile(ecc.BN254, r1cs.NewBuilder, &nilHashCircuit{})
+	assert.Error(err)
+	assert.True(errors.Is(err, ErrNilHash), "expected ErrNilHash, got %v", err)
+}
+
+// verifyOptionsCircuit verifies a signature on the BN254 companion curve with opts, and with
+// WithAssociatedData(AssociatedData...) when AssociatedData is not empty. Its public inputs
+// are A.X, A.Y, the associated data and M, the signature being secret.
+type verifyOptionsCircuit struct {
+	opts           []VerifyOption
+	PublicKey      PublicKey `gnark:",public"`
+	Signature      Signature
+	AssociatedData []frontend.Variable `gnark:",public"`
+	Message        frontend.Variable   `gnark:",public"`
+}
+
+func (circuit *verifyOptionsCircuit) Define(api frontend.API) error {
+	curve, err := twistededwards.NewEdCurve(api, tedwards.BN254)
+	if err != nil {
+		return err
+	}
+	mimc, err := mimc.NewMiMC(api)
+	if err != nil {
+		return err
+	}
+	opts := circuit.opts
+	if len(circuit.AssociatedData) > 0 {
+		opts = append(append([]VerifyOption{}, opts...), WithAssociatedData(circuit.AssociatedData...))
+	}
+	return Verify(curve, circuit.Signature, circuit.Message, circuit.PublicKey, &mimc, opts...)
+}
+
+// verifyOptionsCase is a witness of circuit, and whether it must be accepted.
+type verifyOptionsCase struct {
+	name    string
+	circuit *verifyOptionsCircuit
+	witness verifyOptionsCircuit
+	valid   bool
+}
+
+func runVerifyOptionsCases(assert *test.Assert, cases []verifyOptionsCase) {
+	for _, c := range cases {
+		c := c
+		assert.Run(func(assert *test.Assert) {
+			if c.valid {
+				assert.SolvingSucceeded(c.circuit, &c.witness, test.WithCurves(ecc.BN254))
+			} else {
+				assert.SolvingFailed(c.circuit, &c.witness, test.WithCurves(ecc.BN254))
+			}
+		}, c.name)
+	}
+}
+
+// signWithChallengeOrder signs msg on the BN254 companion curve, absorbing the terms of the
+// challenge in the given order, as an external (non gnark) signer would do.
+func signWithChallengeOrder(privKey signature.Signer, msg *big.Int, order []ChallengeTerm, randomness *rand.Rand) ([]byte, error) {
+	var A edbn254.PointAffine
+	if _, err := A.SetBytes(privKey.Public().Bytes()); err != nil {
+		return nil, err
+	}
+	var scalar big.Int
+	scalar.SetBytes(privKey.Bytes()[fr.Bytes : 2*fr.Bytes])
+
+	return signBN254(&scalar, &A, msg, nil, order, randomness), nil
+}
+
+// signBN254 signs msg with scalar on the BN254 companion curve, using A as public key in the
+// challenge, which absorbs prefix first. A is not required to be [scalar]Base.
+func signBN254(scalar *big.Int, A *edbn254.PointAffine, msg *big.Int, prefix []fr.Element, order []ChallengeTerm, randomness *rand.Rand) []byte {
+	curve := edbn254.GetEdwardsCurve()
+
+	// R = r*Base
+	var r big.Int
+	r.Rand(randomness, &curve.Order)
+	var R edbn254.PointAffine
+	R.ScalarMul(&curve.Base, &r)
+
+	// c = H(terms...)
+	var m fr.Element
+	m.SetBigInt(msg)
+	rx, ry, ax, ay, mb := R.X.Bytes(), R.Y.Bytes(), A.X.Bytes(), A.Y.Bytes(), m.Bytes()
+	terms := [...][]byte{rx[:], ry[:], ax[:], ay[:], mb[:]}
+	h := hash.MIMC_BN254.New()
+	for _, p := range prefix {
+		b := p.Bytes()
+		h.Write(b[:])
+	}
+	for _, t := range order {
+		h.Write(terms[t])
+	}
+	var c big.Int
+	c.SetBytes(h.Sum(nil))
+
+	// S = r + c*scalar mod order
+	var S big.Int
+	S.Mul(&c, scalar).Add(&S, &r).Mod(&S, &curve.Order)
+
+	rBytes := R.Bytes()
+	res := make([]byte, 2*fr.Bytes)
+	copy(res, rBytes[:])
+	S.FillBytes(res[fr.Bytes:])
+	return res
+}
+
+func TestEddsaChallengeOrder(t *testing.T) {
+	assert := test.NewAssert(t)
+
+	randomness := rand.New(rand.NewSource(time.Now().Unix()))
+	privKey, err := eddsa.New(tedwards.BN254, randomness)
+	assert.NoError(err)
+	pubKey := privKey.Public()
+
+	var msg big.Int
+	msg.Rand(randomness, ecc.BN254.Info().Fr.Modulus())
+
+	orders := [][]ChallengeTerm{
+		DefaultChallengeOrder(),
+		{TermRX, TermRY, TermM},
+		{TermAX, TermAY, TermRX, TermRY, TermM},
+	}
+
+	circuits := make([]verifyOptionsCircuit, len(orders))
+	for i := range orders {
+		circuits[i].opts = []VerifyOption{WithChallengeOrder(orders[i]...)}
+	}
+
+	var cases []verifyOptionsCase
+	for i, order := range orders {
+		sig, err := signWithChallengeOrder(privKey, &msg, order, randomness)
+		assert.NoError(err)
+
+		if i == 0 {
+			// the default order must match the native implementation
+			var m fr.Element
+			m.SetBigInt(&msg)
+			mb := m.Bytes()
+			ok, err := pubKey.Verify(sig, mb[:], hash.MIMC_BN254.New())
+			assert.NoError(err)
+			assert.True(ok, "default challenge order doesn't match native verification")
+		}
+
+		var witness verifyOptionsCircuit
+		witness.Message = msg
+		witness.PublicKey.Assign(ecc.BN254, pubKey.Bytes())
+		witness.Signature.Assign(ecc.BN254, sig)
+
+		cases = append(cases,
+			verifyOptionsCase{fmt.Sprintf("order=%v", order), &circuits[i], witness, true},
+			verifyOptionsCase{fmt.Sprintf("order=%v/other", order), &circuits[(i+1)%len(orders)], witness, false},
+		)
+	}
+	runVerifyOptionsCases(assert, cases)
+
+	// neither the default order nor the option alias the caller's slices
+	d := DefaultChallengeOrder()
+	d[0] = TermM
+	assert.Equal(TermRX, DefaultChallengeOrder()[0])
+	var cfg verifyConfig
+	terms := []ChallengeTerm{TermRX, TermRY, TermM}
+	assert.NoError(WithChallengeOrder(terms...)(&cfg))
+	terms[0] = TermAX
+	assert.Equal(TermRX, cfg.challengeOrder[0])
+
+	// invalid orders
+	invalidOrders := [][]ChallengeTerm{
+		{},
+		{TermRX, TermM},
+		{TermRX, TermRY, TermAX, TermAY},
+		{TermRX, TermRY, TermM, TermM},
+		{TermRX, TermRY, TermM, TermM + 1},
+	}
+	for _, order := range invalidOrders {
+		_, err := frontend.Compile(ecc.BN254, r1cs.NewBuilder, &verifyOptionsCircuit{opts: []VerifyOption{WithChallengeOrder(order...)}})
+		assert.Error(err, "challenge order %v should be rejected", order)
+	}
+}
+
+func TestEddsaNonMalleable(t *testing.T) {
+	assert := test.NewAssert(t)
+
+	randomness := rand.New(rand.NewSource(time.Now().Unix()))
+	privKey, err := eddsa.New(tedwards.BN254, randomness)
+	assert.NoError(err)
+
+	var A edbn254.PointAffine
+	_, err = A.SetBytes(privKey.Public().Bytes())
+	assert.NoError(err)
+	var scalar big.Int
+	scalar.SetBytes(privKey.Bytes()[fr.Bytes : 2*fr.Bytes])
+
+	var msg big.Int
+	msg.Rand(randomness, ecc.BN254.Info().Fr.Modulus())
+
+	malleable := &verifyOptionsCircuit{}
+	nonMalleable := &verifyOptionsCircuit{opts: []VerifyOption{WithNonMalleable()}}
+
+	// canonical signature
+	sig := signBN254(&scalar, &A, &msg, nil, DefaultChallengeOrder(), randomness)
+	var canonical verifyOptionsCircuit
+	canonical.Message = msg
+	canonical.PublicKey.A = twistededwards.Point{X: A.X, Y: A.Y}
+	canonical.Signature.Assign(ecc.BN254, sig)
+
+	// (R, S + order)
+	curve := edbn254.GetEdwardsCurve()
+	var S big.Int
+	S.SetBytes(sig[fr.Bytes:]).Add(&S, &curve.Order)
+	shifted := canonical
+	shifted.Signature.S = S
+
+	// signature under A + (0, -1), a point of order 2 away from A
+	AT := stdtest.AddOrder2BN254(A)
+	var lowOrder verifyOptionsCircuit
+	lowOrder.Message = msg
+	lowOrder.PublicKey.A = twistededwards.Point{X: AT.X, Y: AT.Y}
+	lowOrder.Signature.Assign(ecc.BN254, signBN254(&scalar, &AT, &msg, nil, DefaultChallengeOrder(), randomness))
+
+	runVerifyOptionsCases(assert, []verifyOptionsCase{
+		{"canonical", malleable, canonical, true},
+		{"canonical/non-malleable", nonMalleable, canonical, true},
+		{"S+order", malleable, shifted, true},
+		{"S+order/non-malleable", nonMalleable, shifted, false},
+		{"A+T", malleable, lowOrder, true},
+		{"A+T/non-malleable", nonMalleable, lowOrder, false},
+	})
+}
+
+type isValidCircuit struct {
+	PublicKey PublicKey         `gnark:",public"`
+	Signature Signature         `gnark:",public"`
+	Message   frontend.Variable `gnark:",public"`
+	Override  frontend.Variable `gnark:",public"`
+}
+
+func (circuit *isValidCircuit) Define(api frontend.API) error {
+	curve, err := twistededwards.NewEdCurve(api, tedwards.BN254)
+	if err != nil {
+		return err
+	}
+	mimc, err := mimc.NewMiMC(api)
+	if err != nil {
+		return err
+	}
+	valid, err := IsValid(curve, circuit.Signature, circuit.Message, circuit.PublicKey, &mimc)
+	if err != nil {
+		return err
+	}
+	// valid signature OR override
+	api.AssertIsEqual(api.Or(valid, circuit.Override), 1)
+	return nil
+}
+
+func TestEddsaIsValid(t *testing.T) {
+	assert := test.NewAssert(t)
+
+	seed := testutil.SeedFromString("TestEddsaIsValid")
+	privKey, err := eddsa.New(tedwards.BN254, bytes.NewReader(seed[:]))
+	assert.NoError(err)
+
+	var msg fr.Element
+	msg.SetUint64(42)
+	mb := msg.Bytes()
+	sig, err := privKey.Sign(mb[:], hash.MIMC_BN254.New())
+	assert.NoError(err)
+
+	var circuit, witness isValidCircuit
+	witness.PublicKey.Assign(ecc.BN254, privKey.Public().Bytes())
+	witness.Signature.Assign(ecc.BN254, sig)
+
+	for _, override := range []int{0, 1} {
+		witness.Override = override
+
+		witness.Message = msg
+		assert.SolvingSucceeded(&circuit, &witness, test.WithCurves(ecc.BN254))
+
+		witness.Message = 43
+		if override == 1 {
+			assert.SolvingSucceeded(&circuit, &witness, test.WithCurves(ecc.BN254))
+		} else {
+			assert.SolvingFailed(&circuit, &witness, test.WithCurves(ecc.BN254))
+		}
+	}
+
+	// R off the curve is rejected, even with the override
+	offCurve := witness
+	offCurve.Message = msg
+	offCurve.Signature.R = twistededwards.Point{X: 1, Y: 1}
+	assert.SolvingFailed(&circuit, &offCurve, test.WithCurves(ecc.BN254))
+}
+
+// TestPublicInputs pins the order of the public inputs of an eddsa verifier circuit.
+func TestPublicInputs(t *testing.T) {
+	assert := test.NewAssert(t)
+
+	seed := testutil.SeedFromString("TestPublicInputs")
+	privKey, err := eddsa.New(tedwards.BN254, bytes.NewReader(seed[:]))
+	assert.NoError(err)
+
+	msg := big.NewInt(42).Bytes()
+	sig, err := privKey.Sign(msg, hash.MIMC_BN254.New())
+	assert.NoError(err)
+
+	inputs, err := PublicInputs(ecc.BN254, privKey.Public().Bytes(), msg)
+	assert.NoError(err)
+
+	var A edbn254.PointAffine
+	_, err = A.SetBytes(privKey.Public().Bytes())
+	assert.NoError(err)
+	var ax, ay big.Int
+	A.X.ToBigIntRegular(&ax)
+	A.Y.ToBigIntRegular(&ay)
+	assert.Equal([]*big.Int{&ax, &ay, big.NewInt(42)}, inputs)
+
+	// the public witness holds the same values, in the same order
+	assertMatchesWitness := func(inputs []*big.Int, assignment *verifyOptionsCircuit) {
+		w, err := frontend.NewWitness(assignment, ecc.BN254, frontend.PublicOnly())
+		assert.NoError(err)
+		data, err := w.MarshalBinary()
+		assert.NoError(err)
+
+		var expected bytes.Buffer
+		for _, v := range inputs {
+			var e fr.Element
+			e.SetBigInt(v)
+			b := e.Bytes()
+			expected.Write(b[:])
+		}
+		assert.Equal(expected.Bytes(), data[4:], "public witness doesn't match PublicInputs")
+
+		// inputs must be canonical, as the exported solidity verifier requires
+		for _, v := range inputs {
+			assert.True(v.Cmp(ecc.BN254.Info().Fr.Modulus()) < 0, "public input not reduced")
+		}
+	}
+	var assignment verifyOptionsCircuit
+	assignment.PublicKey, assignment.Signature, assignment.Message, err = AssignSignature(ecc.BN254, privKey.Public().Bytes(), sig, msg)
+	assert.NoError(err)
+	assertMatchesWitness(inputs, &assignment)
+
+	// a message larger than the modulus is reduced, as in the witness
+	large := bytes.Repeat([]byte{0xff}, fr.Bytes)
+	largeInputs, err := PublicInputs(ecc.BN254, privKey.Public().Bytes(), large)
+	assert.NoError(err)
+	largeAssignment := assignment
+	largeAssignment.Message = large
+	assertMatchesWitness(largeInputs, &largeAssignment)
+
+	// the proof verifies with these public inputs
+	var circuit verifyOptionsCircuit
+	assert.ProverSucceeded(&circuit, &assignment, test.WithCurves(ecc.BN254))
+
+	_, err = PublicInputs(ecc.BN254, privKey.Public().Bytes()[1:], msg)
+	assert.Error(err)
+	_, err = PublicInputs(ecc.BN254, privKey.Public().Bytes(), make([]byte, fr.Bytes+1))
+	assert.Error(err)
+
+	// curves without a twisted Edwards companion return an error instead of panicking
+	for _, id := range []ecc.ID{ecc.BW6_756, ecc.BLS12_378, ecc.UNKNOWN} {
+		_, err = PublicInputs(id, privKey.Public().Bytes(), msg)
+		assert.Error(err, "curve %d", id)
+	}
+}
+
+func TestEddsaWeakKeys(t *testing.T) {
+	assert := test.NewAssert(t)
+
+	curve := edbn254.GetEdwardsCurve()
+	randomness := rand.New(rand.NewSource(time.Now().Unix()))
+
+	// the 8 points of small order, starting with the identity
+	weakKeys := stdtest.SmallOrderPointsBN254()
+
+	accept := &verifyOptionsCircuit{}
+	reject := &verifyOptionsCircuit{opts: []VerifyOption{WithRejectWeakKeys()}}
+
+	var cases []verifyOptionsCase
+	for i, A := range weakKeys {
+		// (R, S) with R = [S]G verifies any message under a small order key
+		var r big.Int
+		r.Rand(randomness, &curve.Order)
+		var R edbn254.PointAffine
+		R.ScalarMul(&curve.Base, &r)
+
+		var witness verifyOptionsCircuit
+		witness.PublicKey.A = twistededwards.Point{X: A.X, Y: A.Y}
+		witness.Signature.R = twistededwards.Point{X: R.X, Y: R.Y}
+		witness.Signature.S = r
+		witness.Message = randomness.Int63()
+
+		cases = append(cases,
+			verifyOptionsCase{fmt.Sprintf("T%d", i), accept, witness, true},
+			verifyOptionsCase{fmt.Sprintf("T%d/reject", i), reject, witness, false},
+		)
+	}
+
+	// regular keys are accepted
+	privKey, err := eddsa.New(tedwards.BN254, randomness)
+	assert.NoError(err)
+	msg := big.NewInt(42).Bytes()
+	sig, err := privKey.Sign(msg, hash.MIMC_BN254.New())
+	assert.NoError(err)
+
+	var witness verifyOptionsCircuit
+	witness.PublicKey.Assign(ecc.BN254, privKey.Public().Bytes())
+	witness.Signature.Assign(ecc.BN254, sig)
+	witness.Message = msg
+	cases = append(cases, verifyOptionsCase{"regular/reject", reject, witness, true})
+
+	runVerifyOptionsCases(assert, cases)
+}
+
+func TestEddsaAssociatedData(t *testing.T) {
+	assert := test.NewAssert(t)
+
+	seed := testutil.SeedFromString("TestEddsaAssociatedData")
+	privKey, err := eddsa.New(tedwards.BN254, bytes.NewReader(seed[:]))
+	assert.NoError(err)
+
+	// natively, the associated data is prepended to the message
+	var aad [2]fr.Element
+	aad[0].SetUint64(1660000000) // timestamp
+	aad[1].SetUint64(7)          // recipient
+	var msg fr.Element
+	msg.SetUint64(42)
+
+	var signed bytes.Buffer
+	for _, e := range []fr.Element{aad[0], aad[1], msg} {
+		b := e.Bytes()
+		signed.Write(b[:])
+	}
+	sig, err := privKey.Sign(signed.Bytes(), hash.MIMC_BN254.New())
+	assert.NoError(err)
+
+	circuit := &verifyOptionsCircuit{AssociatedData: make([]frontend.Variable, 2)}
+	plain := &verifyOptionsCircuit{}
+
+	var witness verifyOptionsCircuit
+	witness.PublicKey.Assign(ecc.BN254, privKey.Public().Bytes())
+	witness.Signature.Assign(ecc.BN254, sig)
+	witness.AssociatedData = []frontend.Variable{aad[0], aad[1]}
+	witness.Message = msg
+
+	mismatched := witness
+	mismatched.AssociatedData = []frontend.Variable{aad[0], 8}
+
+	swapped := witness
+	swapped.AssociatedData = []frontend.Variable{aad[1], aad[0]}
+
+	// the associated data is not part of the message
+	noAAD := witness
+	noAAD.AssociatedData = nil
+
+	runVerifyOptionsCases(assert, []verifyOptionsCase{
+		{"aad", circuit, witness, true},
+		{"mismatched", circuit, mismatched, false},
+		{"swapped", circuit, swapped, false},
+		{"no aad", plain, noAAD, false},
+	})
+}
+
+func TestEddsaChallengePrefix(t *testing.T) {
+	assert := test.NewAssert(t)
+
+	randomness := rand.New(rand.NewSource(time.Now().Unix()))
+	privKey, err := eddsa.New(tedwards.BN254, randomness)
+	assert.NoError(err)
+
+	var A edbn254.PointAffine
+	_, err = A.SetBytes(privKey.Public().Bytes())
+	assert.NoError(err)
+	var scalar big.Int
+	scalar.SetBytes(privKey.Bytes()[fr.Bytes : 2*fr.Bytes])
+
+	var msg big.Int
+	msg.Rand(randomness, ecc.BN254.Info().Fr.Modulus())
+
+	var session [2]fr.Element
+	session[0].SetUint64(1)
+	session[1].SetUint64(42)
+
+	prefixed := &verifyOptionsCircuit{opts: []VerifyOption{WithChallengePrefix(session[0], session[1])}}
+	otherSession := &verifyOptionsCircuit{opts: []VerifyOption{WithChallengePrefix(session[0], 43)}}
+	swapped := &verifyOptionsCircuit{opts: []VerifyOption{WithChallengePrefix(session[1], session[0])}}
+	plain := &verifyOptionsCircuit{}
+	empty := &verifyOptionsCircuit{opts: []VerifyOption{WithChallengePrefix()}}
+
+	var withPrefix verifyOptionsCircuit
+	withPrefix.Message = msg
+	withPrefix.PublicKey.Assign(ecc.BN254, privKey.Public().Bytes())
+	withPrefix.Signature.Assign(ecc.BN254, signBN254(&scalar, &A, &msg, session[:], DefaultChallengeOrder(), randomness))
+
+	var noPrefix verifyOptionsCircuit
+	noPrefix.Message = msg
+	noPrefix.PublicKey.Assign(ecc.BN254, privKey.Public().Bytes())
+	noPrefix.Signature.Assign(ecc.BN254, signBN254(&scalar, &A, &msg, nil, DefaultChallengeOrder(), randomness))
+
+	runVerifyOptionsCases(assert, []verifyOptionsCase{
+		{"prefix", prefixed, withPrefix, true},
+		{"prefix/other session", otherSession, withPrefix, false},
+		{"prefix/swapped", swapped, withPrefix, false},
+		{"prefix/plain", plain, withPrefix, false},
+		{"no prefix", plain, noPrefix, true},
+		{"no prefix/empty", empty, noPrefix, true},
+		{"no prefix/prefix", prefixed, noPrefix, false},
+	})
+}
+
+type pointMessageCircuit struct {
+	PublicKey PublicKey            `gnark:",public"`
+	Signature Signature            `gnark:",public"`
+	Message   twistededwards.Point `gnark:",public"`
+}
+
+func (circuit *pointMessageCircuit) Define(api frontend.API) error {
+	curve, err := twistededwards.NewEdCurve(api, tedwards.BN254)
+	if err != nil {
+		return err
+	}
+	mimc, err := mimc.NewMiMC(api)
+	if err != nil {
+		return err
+	}
+	return VerifyPoint(curve, circuit.Signature, circuit.Message, circuit.PublicKey, &mimc)
+}
+
+func TestEddsaVerifyPoint(t *testing.T) {
+	assert := test.NewAssert(t)
+
+	seed := testutil.SeedFromString("TestEddsaVerifyPoint")
+	privKey, err := eddsa.New(tedwards.BN254, bytes.NewReader(seed[:]))
+	assert.NoError(err)
+
+	// sign a commitment [k]G, given by its coordinates
+	curve := edbn254.GetEdwardsCurve()
+	var P edbn254.PointAffine
+	P.ScalarMul(&curve.Base, big.NewInt(1337))
+	px, py := P.X.Bytes(), P.Y.Bytes()
+	sig, err := privKey.Sign(append(px[:], py[:]...), hash.MIMC_BN254.New())
+	assert.NoError(err)
+
+	var circuit, witness pointMessageCircuit
+	witness.PublicKey.Assign(ecc.BN254, privKey.Public().Bytes())
+	witness.Signature.Assign(ecc.BN254, sig)
+	witness.Message = twistededwards.Point{X: P.X, Y: P.Y}
+	assert.SolvingSucceeded(&circuit, &witness, test.WithCurves(ecc.BN254))
+
+	// coordinates are absorbed X then Y
+	witness.Message = twistededwards.Point{X: P.Y, Y: P.X}
+	assert.SolvingFailed(&circuit, &witness, test.WithCurves(ecc.BN254))
+
+	// -P is another message
+	var N edbn254.PointAffine
+	N.Neg(&P)
+	witness.Message = twistededwards.Point{X: N.X, Y: N.Y}
+	assert.SolvingFailed(&circuit, &witness, test.WithCurves(ecc.BN254))
+}
+
+func TestAssignSignature(t *testing.T) {
+	assert := test.NewAssert(t)
+
+	seed := testutil.SeedFromString("TestAssignSignature")
+	privKey, err := eddsa.New(tedwards.BN254, bytes.NewReader(seed[:]))
+	assert.NoError(err)
+
+	msg := []byte("gnark")
+	sig, err := privKey.Sign(msg, hash.MIMC_BN254.New())
+	assert.NoError(err)
+	pubKey := privKey.Public().Bytes()
+
+	var witness eddsaCircuit
+	witness.PublicKey, witness.Signature, witness.Message, err = AssignSignature(ecc.BN254, pubKey, sig, msg)
+	assert.NoError(err)
+
+	// same assignment as the Assign helpers
+	var expected eddsaCircuit
+	expected.PublicKey.Assign(ecc.BN254, pubKey)
+	expected.Signature.Assign(ecc.BN254, sig)
+	assert.Equal(expected.PublicKey, witness.PublicKey)
+	assert.Equal(expected.Signature, witness.Signature)
+
+	// round trip through the circuit
+	circuit := eddsaCircuit{curveID: tedwards.BN254}
+	assert.SolvingSucceeded(&circuit, &witness, test.WithCurves(ecc.BN254))
+
+	// message too long
+	_, _, _, err = AssignSignature(ecc.BN254, pubKey, sig, make([]byte, fr.Bytes+1))
+	assert.Error(err)
+
+	// invalid encodings
+	_, _, _, err = AssignSignature(ecc.BN254, pubKey, sig[:fr.Bytes], msg)
+	assert.Error(err)
+	_, _, _, err = AssignSignature(ecc.BN254, pubKey[:fr.Bytes-1], sig, msg)
+	assert.Error(err)
+
+	// (R, S + order)
+	curve := edbn254.GetEdwardsCurve()
+	var S big.Int
+	S.SetBytes(sig[fr.Bytes:]).Add(&S, &curve.Order)
+	shifted := append([]byte{}, sig...)
+	S.FillBytes(shifted[fr.Bytes:])
+	_, _, _, err = AssignSignature(ecc.BN254, pubKey, shifted, msg)
+	assert.True(errors.Is(err, ErrSignatureMalleable), "S + order should be rejected")
+
+	// curves without a twisted Edwards companion return an error instead of panicking
+	for _, id := range []ecc.ID{ecc.BW6_756, ecc.BLS12_378, ecc.UNKNOWN} {
+		_, _, _, err = AssignSignature(id, pubKey, sig, msg)
+		assert.Error(err, "curve %d", id)
+	}
+	_, _, _, err = AssignSignature(ecc.BW6_756, pubKey, sig, msg)
+	assert.EqualError(err, "eddsa: unsupported curve bw6_756")
+}
+
+func TestEddsaEmptyMessage(t *testing.T) {
+	assert := test.NewAssert(t)
+
+	seed := testutil.SeedFromString("TestEddsaEmptyMessage")
+	privKey, err := eddsa.New(tedwards.BN254, bytes.NewReader(seed[:]))
+	assert.NoError(err)
+	pubKey := privKey.Public()
+
+	zero := make([]byte, fr.Bytes)
+	empty := []byte{}
+
+	sigZero, err := privKey.Sign(zero, hash.MIMC_BN254.New())
+	assert.NoError(err)
+	sigEmpty, err := privKey.Sign(empty, hash.MIMC_BN254.New())
+	assert.NoError(err)
+
+	// both are well defined and distinct
+	assert.NotEqual(sigZero, sigEmpty)
+
+	// native round trips
+	ok, err := pubKey.Verify(sigZero, zero, hash.MIMC_BN254.New())
+	assert.NoError(err)
+	assert.True(ok)
+	ok, err = pubKey.Verify(sigEmpty, empty, hash.MIMC_BN254.New())
+	assert.NoError(err)
+	assert.True(ok)
+	ok, err = pubKey.Verify(sigEmpty, zero, hash.MIMC_BN254.New())
+	assert.NoError(err)
+	assert.False(ok)
+
+	// in-circuit, msg = 0 matches the signature of the zero field element only
+	circuit := eddsaCircuit{curveID: tedwards.BN254}
+	{
+		var witness eddsaCircuit
+		witness.Message = 0
+		witness.PublicKey.Assign(ecc.BN254, pubKey.Bytes())
+		witness.Signature.Assign(ecc.BN254, sigZero)
+		assert.SolvingSucceeded(&circuit, &witness, test.WithCurves(ecc.BN254))
+	}
+	{
+		var witness eddsaCircuit
+		witness.Message = 0
+		witness.PublicKey.Assign(ecc.BN254, pubKey.Bytes())
+		witness.Signature.Assign(ecc.BN254, sigEmpty)
+		assert.SolvingFailed(&circuit, &witness, test.WithCurves(ecc.BN254))
+	}
+}
+
+type publicKeyCircuit struct {
+	checkSubgroup bool
+	PublicKey     PublicKey
+}
+
+func (circuit *publicKeyCircuit) Define(api frontend.API) error {
+	curve, err := twistededwards.NewEdCurve(api, tedwards.BN254)
+	if err != nil {
+		return err
+	}
+	AssertValidPublicKey(curve, circuit.PublicKey)
+	if circuit.checkSubgroup {
+		AssertPublicKeyInSubgroup(curve, circuit.PublicKey)
+	}
+	return nil
+}
+
+func TestValidPublicKey(t *testing.T) {
+	assert := test.NewAssert(t)
+
+	seed := testutil.SeedFromString("TestValidPublicKey")
+	privKey, err := eddsa.New(tedwards.BN254, bytes.NewReader(seed[:]))
+	assert.NoError(err)
+
+	var A edbn254.PointAffine
+	_, err = A.SetBytes(privKey.Public().Bytes())
+	assert.NoError(err)
+
+	// A + (0, -1) is on the curve, but not in the prime order subgroup
+	AT := stdtest.AddOrder2BN254(A)
+
+	onCurve := publicKeyCircuit{}
+	inSubgroup := publicKeyCircuit{checkSubgroup: true}
+
+	valid := publicKeyCircuit{PublicKey: PublicKey{A: twistededwards.Point{X: A.X, Y: A.Y}}}
+	assert.SolvingSucceeded(&onCurve, &valid, test.WithCurves(ecc.BN254))
+	assert.SolvingSucceeded(&inSubgroup, &valid, test.WithCurves(ecc.BN254))
+
+	lowOrder := publicKeyCircuit{PublicKey: PublicKey{A: twistededwards.Point{X: AT.X, Y: AT.Y}}}
+	assert.SolvingSucceeded(&onCurve, &lowOrder, test.WithCurves(ecc.BN254))
+	assert.SolvingFailed(&inSubgroup, &lowOrder, test.WithCurves(ecc.BN254))
+
+	var y fr.Element
+	y.SetOne().Add(&y, &A.Y)
+	invalid := publicKeyCircuit{PublicKey: PublicKey{A: twistededwards.Point{X: A.X, Y: y}}}
+	assert.SolvingFailed(&onCurve, &invalid, test.WithCurves(ecc.BN254))
+}
+
+type toyCurveCircuit struct {
+	params    *twistededwards.CurveParams
+	PublicKey PublicKey         `gnark:",public"`
+	Signature Signature         `gnark:",public"`
+	Message   frontend.Variable `gnark:",public"`
+	Valid     frontend.Variable `gnark:",public"`
+}
+
+func (circuit *toyCurveCircuit) Define(api frontend.API) error {
+	curve, err := twistededwards.NewEdCurveWithParams(api, circuit.params)
+	if err != nil {
+		return err
+	}
+	mimc, err := mimc.NewMiMC(api)
+	if err != nil {
+		return err
+	}
+	valid, err := IsValid(curve, circuit.Signature, circuit.Message, circuit.PublicKey, &mimc)
+	if err != nil {
+		return err
+	}
+	api.AssertIsEqual(valid, circuit.Valid)
+	return nil
+}
+
+// TestEddsaToyCurve verifies signatures on the subgroup of order 8 of the BN254 twisted
+// edwards curve, taken as a curve of cofactor 1.
+func TestEddsaToyCurve(t *testing.T) {
+	assert := test.NewAssert(t)
+
+	// points[i] = [i]Base
+	points := stdtest.SmallOrderPointsBN254()
+	params, err := twistededwards.GetCurveParams(tedwards.BN254)
+	assert.NoError(err)
+	toy := &twistededwards.CurveParams{
+		A:        params.A,
+		D:        params.D,
+		Cofactor: big.NewInt(1),
+		Order:    big.NewInt(8),
+		Base:     [2]*big.Int{new(big.Int), new(big.Int)},
+	}
+	points[1].X.ToBigIntRegular(toy.Base[0])
+	points[1].Y.ToBigIntRegular(toy.Base[1])
+
+	// A = [a]Base, R = [r]Base, S = r + H(R, A, M)*a mod 8
+	const a, r = 3, 5
+	A, R := points[a], points[r]
+	var msg fr.Element
+	msg.SetUint64(42)
+	h := hash.MIMC_BN254.New()
+	for _, e := range []fr.Element{R.X, R.Y, A.X, A.Y, msg} {
+		b := e.Bytes()
+		h.Write(b[:])
+	}
+	var S big.Int
+	S.SetBytes(h.Sum(nil)).Mul(&S, big.NewInt(a)).Add(&S, big.NewInt(r)).Mod(&S, toy.Order)
+
+	var witness toyCurveCircuit
+	witness.PublicKey.A = twistededwards.Point{X: A.X, Y: A.Y}
+	witness.Signature.R = twistededwards.Point{X: R.X, Y: R.Y}
+	witness.Signature.S = S
+	witness.Message = msg
+	witness.Valid = 1
+
+	invalid := witness
+	invalid.Signature.S = new(big.Int).Add(&S, big.NewInt(1))
+	invalid.Valid = 0
+
+	circuit := toyCurveCircuit{params: toy}
+	assert.SolvingSucceeded(&circuit, &witness, test.WithCurves(ecc.BN254))
+	assert.SolvingSucceeded(&circuit, &invalid, test.WithCurves(ecc.BN254))
+	invalid.Valid = 1
+	assert.SolvingFailed(&circuit, &invalid, test.WithCurves(ecc.BN254))
+}
+
+type challengeCircuit struct {
+	PublicKey PublicKey
+	R         twistededwards.Point
+	Message   frontend.Variable
+	Challenge frontend.Variable
+}
+
+func (circuit *challengeCircuit) Define(api frontend.API) error {
+	mimc, err := mimc.NewMiMC(api)
+	if err != nil {
+		return err
+	}
+	c := challenge(Signature{R: circuit.R}, circuit.Message, circuit.PublicKey, &mimc, DefaultChallengeOrder(), nil, nil)
+	api.AssertIsEqual(c, circuit.Challenge)
+	return nil
+}
+
+// TestChallengeMatchesNative ensures the in-circuit challenge equals the one computed by the
+// native MiMC over Rx || Ry || Ax || Ay || M, as gnark-crypto's Sign and Verify do.
+func TestChallengeMatchesNative(t *testing.T) {
+	assert := test.NewAssert(t)
+
+	type testData struct {
+		hash  hash.Hash
+		curve tedwards.ID
+	}
+
+	confs := []testData{
+		{hash.MIMC_BN254, tedwards.BN254},
+		{hash.MIMC_BLS12_381, tedwards.BLS12_381},
+		{hash.MIMC_BLS12_377, tedwards.BLS12_377},
+		{hash.MIMC_BW6_761, tedwards.BW6_761},
+		{hash.MIMC_BLS24_315, tedwards.BLS24_315},
+		{hash.MIMC_BW6_633, tedwards.BW6_633},
+	}
+
+	for _, conf := range confs {
+		snarkCurve, err := twistededwards.GetSnarkCurve(conf.curve)
+		assert.NoError(err)
+
+		seed := testutil.SeedFromString("TestChallengeMatchesNative")
+		privKey, err := eddsa.New(conf.curve, bytes.NewReader(seed[:]))
+		assert.NoError(err)
+		pubKey := privKey.Public().Bytes()
+
+		msg := big.NewInt(0xcafe).Bytes()
+		sig, err := privKey.Sign(msg, conf.hash.New())
+		assert.NoError(err)
+
+		// native challenge
+		rx, ry, _, err := parseSignature(snarkCurve, sig)
+		assert.NoError(err)
+		ax, ay, err := parsePoint(snarkCurve, pubKey)
+		assert.NoError(err)
+		h := conf.hash.New()
+		for _, b := range [][]byte{rx, ry, ax, ay, msg} {
+			h.Write(b)
+		}
+		expected := h.Sum(nil)
+
+		var signature Signature
+		signature.Assign(snarkCurve, sig)
+
+		var witness challengeCircuit
+		witness.PublicKey.Assign(snarkCurve, pubKey)
+		witness.R = signature.R
+		witness.Message = msg
+		witness.Challenge = expected
+		assert.SolvingSucceeded(&challengeCircuit{}, &witness, test.WithCurves(snarkCurve))
+
+		witness.Challenge = 1
+		assert.SolvingFailed(&challengeCircuit{}, &witness, test.WithCurves(snarkCurve))
+	}
+}
@@ -0,0 +1,153 @@
+/*
+Copyright © 2020 ConsenSys
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package eddsa_test
+
+import (
+	"bytes"
+	"fmt"
+	"math/big"
+	"os"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	tedwards "github.com/consensys/gnark-crypto/ecc/twistededwards"
+	"github.com/consensys/gnark-crypto/hash"
+	"github.com/consensys/gnark-crypto/signature/eddsa"
+	"github.com/consensys/gnark/backend/groth16"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/frontend/cs/r1cs"
+	"github.com/consensys/gnark/std/algebra/twistededwards"
+	"github.com/consensys/gnark/std/hash/mimc"
+	geddsa "github.com/consensys/gnark/std/signature/eddsa"
+	"github.com/consensys/gnark/std/signature/eddsa/testutil"
+)
+
+// verifierCircuit exposes the public key, the signature and the message as public inputs.
+// Leaves are laid out in the public witness in declaration order, depth first.
+type verifierCircuit struct {
+	PublicKey geddsa.PublicKey  `gnark:",public"`
+	Signature geddsa.Signature  `gnark:",public"`
+	Message   frontend.Variable `gnark:",public"`
+}
+
+func (circuit *verifierCircuit) Define(api frontend.API) error {
+	curve, err := twistededwards.NewEdCurve(api, tedwards.BN254)
+	if err != nil {
+		return err
+	}
+	h, err := mimc.NewMiMC(api)
+	if err != nil {
+		return err
+	}
+	return geddsa.Verify(curve, circuit.Signature, circuit.Message, circuit.PublicKey, &h)
+}
+
+// This example shows how the public components of the gadget land in the public witness.
+// The resulting public input vector is A.X, A.Y, R.X, R.Y, S, M: this is the order in which
+// a groth16 verifier (for instance an exported Solidity contract) expects them.
+func ExamplePublicKey_Assign() {
+	seed := testutil.SeedFromString("example")
+	privKey, err := eddsa.New(tedwards.BN254, bytes.NewReader(seed[:]))
+	if err != nil {
+		panic(err)
+	}
+
+	msg := big.NewInt(42).Bytes()
+	signature, err := privKey.Sign(msg, hash.MIMC_BN254.New())
+	if err != nil {
+		panic(err)
+	}
+
+	var assignment verifierCircuit
+	assignment.PublicKey.Assign(ecc.BN254, privKey.Public().Bytes())
+	assignment.Signature.Assign(ecc.BN254, signature)
+	assignment.Message = msg
+
+	w, err := frontend.NewWitness(&assignment, ecc.BN254, frontend.PublicOnly())
+	if err != nil {
+		panic(err)
+	}
+	if err := w.Schema.WriteSequence(os.Stdout); err != nil {
+		panic(err)
+	}
+	fmt.Println("nb public inputs:", w.Schema.NbPublic)
+
+	// Output:
+	// public:
+	// PublicKey_A_X
+	// PublicKey_A_Y
+	// Signature_R_X
+	// Signature_R_Y
+	// Signature_S
+	// Message
+	// secret:
+	// nb public inputs: 6
+}
+
+// This example runs the whole flow: a message is signed natively with gnark-crypto, the
+// signature is verified in a circuit, and a groth16 proof of that verification is produced
+// and checked. Any mismatch between the native and in-circuit conventions makes it fail.
+func Example() {
+	// generate a key and sign a message natively
+	seed := testutil.SeedFromString("end-to-end")
+	privKey, err := eddsa.New(tedwards.BN254, bytes.NewReader(seed[:]))
+	if err != nil {
+		panic(err)
+	}
+	msg := big.NewInt(1337).Bytes()
+	signature, err := privKey.Sign(msg, hash.MIMC_BN254.New())
+	if err != nil {
+		panic(err)
+	}
+
+	// compile the verifier circuit and run the groth16 setup
+	ccs, err := frontend.Compile(ecc.BN254, r1cs.NewBuilder, &verifierCircuit{})
+	if err != nil {
+		panic(err)
+	}
+	pk, vk, err := groth16.Setup(ccs)
+	if err != nil {
+		panic(err)
+	}
+
+	// assign the native values to the circuit
+	var assignment verifierCircuit
+	assignment.PublicKey.Assign(ecc.BN254, privKey.Public().Bytes())
+	assignment.Signature.Assign(ecc.BN254, signature)
+	assignment.Message = msg
+
+	witness, err := frontend.NewWitness(&assignment, ecc.BN254)
+	if err != nil {
+		panic(err)
+	}
+	publicWitness, err := witness.Public()
+	if err != nil {
+		panic(err)
+	}
+
+	// prove and verify
+	proof, err := groth16.Prove(ccs, pk, witness)
+	if err != nil {
+		panic(err)
+	}
+	if err := groth16.Verify(proof, vk, publicWitness); err != nil {
+		panic(err)
+	}
+	fmt.Println("proof verified")
+
+	// Output:
+	// proof verified
+}
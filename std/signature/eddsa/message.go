@@ -0,0 +1,159 @@
+/*
+Copyright © 2020 ConsenSys
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package eddsa
+
+import (
+	"errors"
+	"math/big"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/std/algebra/twistededwards"
+	"github.com/consensys/gnark/std/hash"
+	"github.com/consensys/gnark/std/math/bits"
+)
+
+// ErrMessageTooLong is returned when the bits to pack don't fit in a message.
+var ErrMessageTooLong = errors.New("eddsa: message doesn't fit in a field element")
+
+// MessageBits returns the number of bits that can be packed in a message on curveID, that
+// is the bit size of the scalar field minus 1, so that any packed message is a canonical
+// field element.
+func MessageBits(curveID ecc.ID) int {
+	return curveID.Info().Fr.Bits - 1
+}
+
+// PackMessage packs bits into a message to be signed natively (the big endian, fixed size
+// encoding of the returned integer). Bits are packed least significant first: bits[i] is
+// the coefficient of 2^i. It returns an error if len(bits) > MessageBits(curveID): bits
+// are never silently reduced modulo the field.
+//
+// The in-circuit counterpart is MessageFromBits.
+func PackMessage(curveID ecc.ID, bits []bool) (*big.Int, error) {
+	if len(bits) > MessageBits(curveID) {
+		return nil, ErrMessageTooLong
+	}
+	m := new(big.Int)
+	for i, b := range bits {
+		if b {
+			m.SetBit(m, i, 1)
+		}
+	}
+	return m, nil
+}
+
+// PackMessageBytes packs the bits of buf into a message, as PackMessage. The bits of buf[i]
+// are the bits 8i to 8i+7 of the message, least significant first: the message is the
+// little endian integer of buf.
+func PackMessageBytes(curveID ecc.ID, buf []byte) (*big.Int, error) {
+	if 8*len(buf) > MessageBits(curveID) {
+		return nil, ErrMessageTooLong
+	}
+	le := make([]byte, len(buf))
+	for i := range buf {
+		le[len(buf)-1-i] = buf[i]
+	}
+	return new(big.Int).SetBytes(le), nil
+}
+
+// UnpackMessage returns the nbBits bits of a message packed with PackMessage. It returns an
+// error if the message doesn't fit in nbBits bits.
+func UnpackMessage(curveID ecc.ID, m *big.Int, nbBits int) ([]bool, error) {
+	if nbBits > MessageBits(curveID) || m.Sign() < 0 || m.BitLen() > nbBits {
+		return nil, ErrMessageTooLong
+	}
+	res := make([]bool, nbBits)
+	for i := range res {
+		res[i] = m.Bit(i) == 1
+	}
+	return res, nil
+}
+
+// MessageFromBits packs bits into a message in-circuit, with the same convention as
+// PackMessage: bits[i] is the coefficient of 2^i. Each bit is constrained to be boolean.
+// It returns an error if len(bits) exceeds MessageBits.
+func MessageFromBits(api frontend.API, b []frontend.Variable) (frontend.Variable, error) {
+	if len(b) > MessageBits(api.Compiler().Curve()) {
+		return nil, ErrMessageTooLong
+	}
+	if len(b) == 0 {
+		return 0, nil
+	}
+	return bits.FromBinary(api, b), nil
+}
+
+// PackMessageChunks packs an arbitrary number of bits into messages, as PackMessage: the
+// bits are split into chunks of MessageBits(curveID) bits (the last one may be shorter),
+// chunks[j] packing bits[j*MessageBits(curveID):]. No bits yield a single zero chunk.
+//
+// The in-circuit counterpart is MessageChunksFromBits.
+func PackMessageChunks(curveID ecc.ID, bits []bool) []*big.Int {
+	chunkSize := MessageBits(curveID)
+	chunks := make([]*big.Int, 0, (len(bits)+chunkSize-1)/chunkSize+1)
+	for len(chunks) == 0 || len(bits) > 0 {
+		n := len(bits)
+		if n > chunkSize {
+			n = chunkSize
+		}
+		m, _ := PackMessage(curveID, bits[:n]) // n <= chunkSize
+		chunks = append(chunks, m)
+		bits = bits[n:]
+	}
+	return chunks
+}
+
+// MessageBytesFromBits returns the message to sign natively for bits to verify with
+// VerifyBits: the concatenation of the fixed size (fr.Bytes), big endian encodings of the
+// chunks returned by PackMessageChunks.
+func MessageBytesFromBits(curveID ecc.ID, bits []bool) []byte {
+	sizeFr := curveID.Info().Fr.Bytes
+	chunks := PackMessageChunks(curveID, bits)
+	res := make([]byte, len(chunks)*sizeFr)
+	for j, c := range chunks {
+		c.FillBytes(res[j*sizeFr : (j+1)*sizeFr])
+	}
+	return res
+}
+
+// MessageChunksFromBits packs bits into messages in-circuit, with the same convention as
+// PackMessageChunks. Each bit is constrained to be boolean.
+func MessageChunksFromBits(api frontend.API, b []frontend.Variable) []frontend.Variable {
+	chunkSize := MessageBits(api.Compiler().Curve())
+	chunks := make([]frontend.Variable, 0, (len(b)+chunkSize-1)/chunkSize+1)
+	for len(chunks) == 0 || len(b) > 0 {
+		n := len(b)
+		if n > chunkSize {
+			n = chunkSize
+		}
+		m, _ := MessageFromBits(api, b[:n]) // n <= chunkSize
+		chunks = append(chunks, m)
+		b = b[n:]
+	}
+	return chunks
+}
+
+// VerifyBits verifies an eddsa signature of a message given by its bits, packed with
+// MessageChunksFromBits. The chunks are absorbed in order as the message: the challenge is
+// H(Rx, Ry, Ax, Ay, chunks[0], ..., chunks[n-1]) with the default order and no associated
+// data.
+//
+// Natively, this is a gnark-crypto signature of MessageBytesFromBits(curveID, bits).
+func VerifyBits(curve twistededwards.Curve, sig Signature, b []frontend.Variable, pubKey PublicKey, hash hash.Hash, opts ...VerifyOption) error {
+	chunks := MessageChunksFromBits(curve.API(), b)
+	n := len(chunks)
+	return Verify(curve, sig, chunks[n-1], pubKey, hash, append(append([]VerifyOption{}, opts...), withMessagePrefix(chunks[:n-1]...))...)
+}
@@ -0,0 +1,234 @@
+/*
+Copyright © 2020 ConsenSys
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package eddsa
+
+import (
+	"errors"
+	"math/big"
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark-crypto/ecc/bn254/fr"
+	tedwards "github.com/consensys/gnark-crypto/ecc/twistededwards"
+	"github.com/consensys/gnark-crypto/hash"
+	"github.com/consensys/gnark-crypto/signature/eddsa"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/std/algebra/twistededwards"
+	"github.com/consensys/gnark/std/hash/mimc"
+	"github.com/consensys/gnark/test"
+)
+
+type packedMessageCircuit struct {
+	Bits      []frontend.Variable
+	PublicKey PublicKey `gnark:",public"`
+	Signature Signature `gnark:",public"`
+}
+
+func (circuit *packedMessageCircuit) Define(api frontend.API) error {
+	curve, err := twistededwards.NewEdCurve(api, tedwards.BN254)
+	if err != nil {
+		return err
+	}
+	mimc, err := mimc.NewMiMC(api)
+	if err != nil {
+		return err
+	}
+	msg, err := MessageFromBits(api, circuit.Bits)
+	if err != nil {
+		return err
+	}
+	return Verify(curve, circuit.Signature, msg, circuit.PublicKey, &mimc)
+}
+
+func TestPackMessage(t *testing.T) {
+	assert := test.NewAssert(t)
+
+	randomness := rand.New(rand.NewSource(time.Now().Unix()))
+	nbBits := MessageBits(ecc.BN254)
+	assert.Equal(fr.Bits-1, nbBits)
+
+	// round trip with boundary bit counts
+	for _, n := range []int{0, 1, 8, nbBits - 1, nbBits} {
+		b := make([]bool, n)
+		for i := range b {
+			b[i] = randomness.Intn(2) == 1
+		}
+		m, err := PackMessage(ecc.BN254, b)
+		assert.NoError(err)
+		assert.True(m.Cmp(ecc.BN254.Info().Fr.Modulus()) < 0)
+		unpacked, err := UnpackMessage(ecc.BN254, m, n)
+		assert.NoError(err)
+		assert.Equal(b, unpacked)
+	}
+
+	// all ones is the largest packed message
+	ones := make([]bool, nbBits)
+	for i := range ones {
+		ones[i] = true
+	}
+	m, err := PackMessage(ecc.BN254, ones)
+	assert.NoError(err)
+	expected := new(big.Int).Lsh(big.NewInt(1), uint(nbBits))
+	assert.Equal(0, m.Cmp(expected.Sub(expected, big.NewInt(1))))
+
+	// bits are never reduced modulo the field
+	_, err = PackMessage(ecc.BN254, make([]bool, nbBits+1))
+	assert.True(errors.Is(err, ErrMessageTooLong))
+	_, err = UnpackMessage(ecc.BN254, m, nbBits-1)
+	assert.True(errors.Is(err, ErrMessageTooLong))
+
+	// bytes are packed as a little endian integer
+	m, err = PackMessageBytes(ecc.BN254, []byte{0x01, 0x02})
+	assert.NoError(err)
+	assert.Equal(int64(0x0201), m.Int64())
+	_, err = PackMessageBytes(ecc.BN254, make([]byte, fr.Bytes))
+	assert.True(errors.Is(err, ErrMessageTooLong))
+
+	// a signature of a natively packed message verifies against the in-circuit packing
+	privKey, err := eddsa.New(tedwards.BN254, randomness)
+	assert.NoError(err)
+
+	const nbFlags = 13
+	flags := make([]bool, nbFlags)
+	for i := range flags {
+		flags[i] = randomness.Intn(2) == 1
+	}
+	m, err = PackMessage(ecc.BN254, flags)
+	assert.NoError(err)
+	var e fr.Element
+	e.SetBigInt(m)
+	mb := e.Bytes()
+	sig, err := privKey.Sign(mb[:], hash.MIMC_BN254.New())
+	assert.NoError(err)
+
+	circuit := packedMessageCircuit{Bits: make([]frontend.Variable, nbFlags)}
+	witness := packedMessageCircuit{Bits: make([]frontend.Variable, nbFlags)}
+	for i, f := range flags {
+		witness.Bits[i] = 0
+		if f {
+			witness.Bits[i] = 1
+		}
+	}
+	witness.PublicKey.Assign(ecc.BN254, privKey.Public().Bytes())
+	witness.Signature.Assign(ecc.BN254, sig)
+	assert.SolvingSucceeded(&circuit, &witness, test.WithCurves(ecc.BN254))
+
+	// flipping a bit invalidates the signature
+	witness.Bits[nbFlags-1] = 1 - witness.Bits[nbFlags-1].(int)
+	assert.SolvingFailed(&circuit, &witness, test.WithCurves(ecc.BN254))
+}
+
+type bitsMessageCircuit struct {
+	Bits      []frontend.Variable
+	Chunks    []frontend.Variable `gnark:",public"`
+	PublicKey PublicKey           `gnark:",public"`
+	Signature Signature           `gnark:",public"`
+}
+
+func (circuit *bitsMessageCircuit) Define(api frontend.API) error {
+	curve, err := twistededwards.NewEdCurve(api, tedwards.BN254)
+	if err != nil {
+		return err
+	}
+	mimc, err := mimc.NewMiMC(api)
+	if err != nil {
+		return err
+	}
+
+	chunks := MessageChunksFromBits(api, circuit.Bits)
+	if len(chunks) != len(circuit.Chunks) {
+		return errors.New("unexpected number of chunks")
+	}
+	for i := range chunks {
+		api.AssertIsEqual(chunks[i], circuit.Chunks[i])
+	}
+
+	return VerifyBits(curve, circuit.Signature, circuit.Bits, circuit.PublicKey, &mimc)
+}
+
+func TestPackMessageChunks(t *testing.T) {
+	assert := test.NewAssert(t)
+
+	randomness := rand.New(rand.NewSource(time.Now().Unix()))
+	chunkSize := MessageBits(ecc.BN254)
+
+	randomBits := func(n int) []bool {
+		b := make([]bool, n)
+		for i := range b {
+			b[i] = randomness.Intn(2) == 1
+		}
+		return b
+	}
+
+	// chunks of MessageBits bits, the last one possibly shorter
+	for _, n := range []int{0, 1, chunkSize, chunkSize + 1, 2*chunkSize + 5} {
+		b := randomBits(n)
+		chunks := PackMessageChunks(ecc.BN254, b)
+		nbChunks := (n + chunkSize - 1) / chunkSize
+		if n == 0 {
+			nbChunks = 1
+		}
+		assert.Equal(nbChunks, len(chunks), "%d bits", n)
+
+		var unpacked []bool
+		for j, c := range chunks {
+			size := chunkSize
+			if j == len(chunks)-1 {
+				size = n - j*chunkSize
+			}
+			u, err := UnpackMessage(ecc.BN254, c, size)
+			assert.NoError(err)
+			unpacked = append(unpacked, u...)
+		}
+		assert.Equal(len(b), len(unpacked))
+		for i := range b {
+			assert.Equal(b[i], unpacked[i])
+		}
+		assert.Equal(len(chunks)*fr.Bytes, len(MessageBytesFromBits(ecc.BN254, b)))
+	}
+
+	// a message of 2 chunks, signed natively and verified against the in-circuit packing
+	const nbBits = 300
+	b := randomBits(nbBits)
+	chunks := PackMessageChunks(ecc.BN254, b)
+
+	privKey, err := eddsa.New(tedwards.BN254, randomness)
+	assert.NoError(err)
+	sig, err := privKey.Sign(MessageBytesFromBits(ecc.BN254, b), hash.MIMC_BN254.New())
+	assert.NoError(err)
+
+	circuit := bitsMessageCircuit{Bits: make([]frontend.Variable, nbBits), Chunks: make([]frontend.Variable, len(chunks))}
+	witness := bitsMessageCircuit{Bits: make([]frontend.Variable, nbBits), Chunks: make([]frontend.Variable, len(chunks))}
+	for i := range b {
+		witness.Bits[i] = 0
+		if b[i] {
+			witness.Bits[i] = 1
+		}
+	}
+	for i := range chunks {
+		witness.Chunks[i] = chunks[i]
+	}
+	witness.PublicKey.Assign(ecc.BN254, privKey.Public().Bytes())
+	witness.Signature.Assign(ecc.BN254, sig)
+	assert.SolvingSucceeded(&circuit, &witness, test.WithCurves(ecc.BN254))
+
+	// flipping a bit of the first chunk invalidates the signature
+	witness.Bits[0] = 1 - witness.Bits[0].(int)
+	assert.SolvingFailed(&circuit, &witness, test.WithCurves(ecc.BN254))
+}
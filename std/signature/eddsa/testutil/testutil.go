@@ -0,0 +1,34 @@
+/*
+Copyright © 2020 ConsenSys
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package testutil provides helpers to build reproducible eddsa test fixtures.
+package testutil
+
+import (
+	"golang.org/x/crypto/blake2b"
+)
+
+// SeedFromString returns a deterministic 32 bytes seed derived from s (blake2b-256 of s).
+//
+// The result can be fed to a key generator expecting a source of randomness, for example
+//
+//	seed := testutil.SeedFromString("alice")
+//	privKey, err := eddsa.New(tedwards.BN254, bytes.NewReader(seed[:]))
+//
+// It must only be used to build test fixtures, never to derive production keys.
+func SeedFromString(s string) [32]byte {
+	return blake2b.Sum256([]byte(s))
+}
@@ -0,0 +1,82 @@
+/*
+Copyright © 2020 ConsenSys
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package testutil
+
+import (
+	"bytes"
+	"encoding/hex"
+	"strings"
+	"testing"
+
+	tedwards "github.com/consensys/gnark-crypto/ecc/twistededwards"
+	"github.com/consensys/gnark-crypto/hash"
+	"github.com/consensys/gnark-crypto/signature/eddsa"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSeedFromString(t *testing.T) {
+	assert := require.New(t)
+
+	// same input, same seed
+	assert.Equal(SeedFromString("alice"), SeedFromString("alice"))
+
+	// different inputs, different seeds
+	assert.NotEqual(SeedFromString("alice"), SeedFromString("bob"))
+
+	// blake2b-256 of the empty string
+	empty := SeedFromString("")
+	assert.Equal("0e5751c026e543b2e8ab2eb06099daa1d1e5df47778f7787faab45cdf12fe3a8", hex.EncodeToString(empty[:]))
+
+	// seeds yield reproducible key pairs
+	s1, s2 := SeedFromString("alice"), SeedFromString("alice")
+	k1, err := eddsa.New(tedwards.BN254, bytes.NewReader(s1[:]))
+	assert.NoError(err)
+	k2, err := eddsa.New(tedwards.BN254, bytes.NewReader(s2[:]))
+	assert.NoError(err)
+	assert.Equal(k1.Bytes(), k2.Bytes())
+}
+
+func TestLoadVectors(t *testing.T) {
+	assert := require.New(t)
+
+	// the sample vectors are reproduced by the native implementation
+	vectors := BN254MiMCVectors()
+	assert.Len(vectors, 4)
+	for _, v := range vectors {
+		privKey, err := eddsa.New(tedwards.BN254, bytes.NewReader(v.Seed))
+		assert.NoError(err)
+		sig, err := privKey.Sign(v.Message, hash.MIMC_BN254.New())
+		assert.NoError(err)
+		assert.Equal(v.Signature, sig)
+	}
+
+	// comments and blank lines are skipped
+	vectors, err := LoadVectors(strings.NewReader("# seed,message,signature\n\n00,01,02\n"))
+	assert.NoError(err)
+	assert.Equal([]TestVector{{Seed: []byte{0}, Message: []byte{1}, Signature: []byte{2}}}, vectors)
+
+	// malformed inputs
+	for _, in := range []string{
+		"00,01\n",
+		"00,01,02,03\n",
+		"00,zz,02\n",
+		"00,01,0\n",
+	} {
+		_, err := LoadVectors(strings.NewReader(in))
+		assert.Error(err, "%q should be rejected", in)
+	}
+}
@@ -0,0 +1,81 @@
+/*
+Copyright © 2020 ConsenSys
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package testutil
+
+import (
+	"bytes"
+	_ "embed"
+	"encoding/csv"
+	"encoding/hex"
+	"fmt"
+	"io"
+)
+
+// TestVector is a signature known answer test: Signature is the expected signature of
+// Message by the key pair generated from Seed.
+type TestVector struct {
+	Seed      []byte
+	Message   []byte
+	Signature []byte
+}
+
+//go:embed testdata/bn254_mimc.csv
+var bn254MiMCVectors []byte
+
+// LoadVectors parses test vectors from r.
+//
+// The input is CSV with three hex encoded columns, and no header:
+//
+//	seed,message,signature
+//
+// where seed is the randomness fed to the key generator (for instance
+// eddsa.New(tedwards.BN254, bytes.NewReader(seed))), message is the signed message and
+// signature is the expected signature, as returned by Sign. Lines starting with # are
+// comments. The curve and the hash function are not part of the format: a file holds
+// vectors for a single (curve, hash) pair, which should be stated in a comment.
+func LoadVectors(r io.Reader) ([]TestVector, error) {
+	reader := csv.NewReader(r)
+	reader.Comment = '#'
+	reader.FieldsPerRecord = 3
+
+	records, err := reader.ReadAll()
+	if err != nil {
+		return nil, err
+	}
+
+	vectors := make([]TestVector, len(records))
+	for i, record := range records {
+		fields := [...]*[]byte{&vectors[i].Seed, &vectors[i].Message, &vectors[i].Signature}
+		for j, field := range record {
+			if *fields[j], err = hex.DecodeString(field); err != nil {
+				return nil, fmt.Errorf("vector %d, column %d: %w", i, j, err)
+			}
+		}
+	}
+
+	return vectors, nil
+}
+
+// BN254MiMCVectors returns the sample test vectors on twistededwards.BN254, with
+// hash.MIMC_BN254 as hash function. Messages are encoded field elements.
+func BN254MiMCVectors() []TestVector {
+	vectors, err := LoadVectors(bytes.NewReader(bn254MiMCVectors))
+	if err != nil {
+		panic(err)
+	}
+	return vectors
+}